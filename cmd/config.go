@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	configFile   string = "config"
+	repositories string = "repositories"
+)
+
+// repositoryConfig holds the settings which only
+// apply to a given repository in a config file:
+//
+//	repositories:
+//	  - owner: cguertin14
+//	    name: k3s-ansible-ha
+//	    deny-versions: ["v1.30.2"]
+//	    pin-version: v1.30.9
+type repositoryConfig struct {
+	Owner        string   `mapstructure:"owner"`
+	Name         string   `mapstructure:"name"`
+	DenyVersions []string `mapstructure:"deny-versions"`
+	PinVersion   string   `mapstructure:"pin-version"`
+}
+
+// readConfig
+//
+// Reads the config file given with the --config
+// flag, if any. Its keys are the same as the flags.
+func readConfig(v *viper.Viper) error {
+	path := v.GetString(configFile)
+	if path == "" {
+		return nil
+	}
+
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("error when reading config file %q: %s", path, err)
+	}
+
+	return nil
+}
+
+// repositorySettings
+//
+// Returns the config entry of the given
+// repository, or an empty one if there is none.
+func repositorySettings(v *viper.Viper, owner, name string) (repositoryConfig, error) {
+	var entries []repositoryConfig
+	if err := v.UnmarshalKey(repositories, &entries); err != nil {
+		return repositoryConfig{}, fmt.Errorf("error when parsing %q from config file: %s", repositories, err)
+	}
+
+	for _, entry := range entries {
+		if entry.Owner == owner && entry.Name == name {
+			return entry, nil
+		}
+	}
+
+	return repositoryConfig{}, nil
+}
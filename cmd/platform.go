@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cguertin14/k3supdater/pkg/platform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	platformName      string = "platform"
+	platformURL       string = "platform-url"
+	gitlabAccessToken string = "GITLAB_ACCESS_TOKEN"
+	giteaAccessToken  string = "GITEA_ACCESS_TOKEN"
+	bitbucketUsername string = "BITBUCKET_USERNAME"
+	bitbucketToken    string = "BITBUCKET_ACCESS_TOKEN"
+)
+
+// Supported platforms
+const (
+	githubPlatform  string = "github"
+	gitlabPlatform  string = "gitlab"
+	giteaPlatform   string = "gitea"
+	forgejoPlatform string = "forgejo"
+
+	bitbucketPlatform       string = "bitbucket"
+	bitbucketServerPlatform string = "bitbucket-server"
+)
+
+// newPlatform
+//
+// Returns the platform hosting the repository, or
+// nil for github, which is the updater's default.
+func newPlatform(v *viper.Viper) (platform.Platform, error) {
+	switch name := v.GetString(platformName); name {
+	case githubPlatform:
+		return nil, nil
+	case gitlabPlatform:
+		return platform.NewGitLab(v.GetString(platformURL), v.GetString(gitlabAccessToken), nil), nil
+	case giteaPlatform, forgejoPlatform:
+		// Forgejo is a fork of gitea, with the same API
+		gitea, err := platform.NewGitea(v.GetString(platformURL), v.GetString(giteaAccessToken), nil)
+		if err != nil {
+			return nil, fmt.Errorf("error when configuring %s: %s, set --%s", name, err, platformURL)
+		}
+		return gitea, nil
+	case bitbucketPlatform:
+		return platform.NewBitbucketCloud(v.GetString(platformURL), v.GetString(bitbucketUsername), v.GetString(bitbucketToken), nil), nil
+	case bitbucketServerPlatform:
+		// Bitbucket data center shares the server API
+		bitbucket, err := platform.NewBitbucketServer(v.GetString(platformURL), v.GetString(bitbucketUsername), v.GetString(bitbucketToken), nil)
+		if err != nil {
+			return nil, fmt.Errorf("error when configuring %s: %s, set --%s", name, err, platformURL)
+		}
+		return bitbucket, nil
+	default:
+		return nil, fmt.Errorf("unsupported platform %q, use one of: %s", name, strings.Join([]string{
+			githubPlatform, gitlabPlatform, giteaPlatform, forgejoPlatform, bitbucketPlatform, bitbucketServerPlatform,
+		}, ", "))
+	}
+}
+
+// addPlatformFlags
+//
+// Adds the flags selecting the platform
+// hosting the repository to a command.
+func addPlatformFlags(cmd *cobra.Command) {
+	cmd.Flags().String(platformName, githubPlatform, "The platform hosting the repository (i.e.: github, gitlab, gitea, forgejo, bitbucket, bitbucket-server). Releases are always read from github.")
+	cmd.Flags().String(platformURL, "", "The url of a self-hosted platform instance (i.e.: https://gitlab.example.com). Defaults to "+platform.DefaultGitLabURL+" on gitlab and "+platform.DefaultBitbucketURL+" on bitbucket, required on gitea, forgejo and bitbucket-server.")
+}
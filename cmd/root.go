@@ -10,7 +10,10 @@ var (
 )
 
 func init() {
+	rootCmd.PersistentFlags().String(configFile, "", "The path of a yaml config file, whose keys are the same as the flags.")
+
 	rootCmd.AddCommand(updateCmd)
+	rootCmd.AddCommand(scanCmd)
 }
 
 func Execute() error {
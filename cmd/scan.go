@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cguertin14/k3supdater/pkg/updater"
+	"github.com/cguertin14/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	scanDir    string = "dir"
+	scanOutput string = "output"
+)
+
+var (
+	scanCmd = &cobra.Command{
+		Use:           "scan",
+		Short:         "Find every k3s version pinned in a repository",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE:          scan,
+	}
+)
+
+// scanConfig is the config file
+// written from the scan results.
+type scanConfig struct {
+	RepoOwner         string   `yaml:"repo-owner,omitempty"`
+	RepoName          string   `yaml:"repo-name,omitempty"`
+	RepoBranch        string   `yaml:"repo-branch,omitempty"`
+	VersionKey        string   `yaml:"version-key,omitempty"`
+	GroupVarsFilepath []string `yaml:"group-vars-filepath"`
+}
+
+func scan(cmd *cobra.Command, args []string) (err error) {
+	ctx := cmd.Context()
+
+	v := viper.New()
+	v.AutomaticEnv()
+	if err = v.BindPFlags(cmd.Flags()); err != nil {
+		return fmt.Errorf("error when parsing flags: %s", err)
+	}
+
+	// A local checkout needs no repository
+	if v.GetString(scanDir) == "" {
+		for _, key := range []string{repoOwner, repoName} {
+			if v.GetString(key) == "" {
+				return fmt.Errorf("required flag %q not set", key)
+			}
+		}
+	}
+
+	// init logger
+	ctxLogger := logger.Initialize(logger.Config{
+		Level:     "info",
+		Formatter: logger.ServiceFormatter,
+	})
+	ctx = context.WithValue(ctx, logger.CtxKey, ctxLogger)
+
+	repoPlatform, err := newPlatform(v)
+	if err != nil {
+		return
+	}
+
+	// create business logic client here
+	client := updater.NewClient(ctx, updater.Dependencies{
+		Platform:    repoPlatform,
+		AccessToken: v.GetString(githubAccessToken),
+	})
+
+	locations, err := client.Scan(ctx, updater.ScanRequest{
+		Repo: updater.Repository{
+			Owner:  v.GetString(repoOwner),
+			Name:   v.GetString(repoName),
+			Branch: v.GetString(repoBranch),
+		},
+		Dir: v.GetString(scanDir),
+	})
+	if err != nil {
+		return fmt.Errorf("error when scanning: %s", err)
+	}
+	if len(locations) == 0 {
+		return fmt.Errorf("error when scanning: no k3s version found")
+	}
+
+	if err = printLocations(cmd.OutOrStdout(), locations); err != nil {
+		return
+	}
+
+	output := v.GetString(scanOutput)
+	if output == "" {
+		return nil
+	}
+
+	content, err := buildScanConfig(scanConfig{
+		RepoOwner:  v.GetString(repoOwner),
+		RepoName:   v.GetString(repoName),
+		RepoBranch: v.GetString(repoBranch),
+	}, locations)
+	if err != nil {
+		return
+	}
+	if err = os.WriteFile(output, content, 0o644); err != nil {
+		return fmt.Errorf("error when writing config file %q: %s", output, err)
+	}
+
+	return nil
+}
+
+// printLocations
+//
+// Prints the scan results as a table.
+func printLocations(w io.Writer, locations []updater.Location) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tTYPE\tKEY\tVERSION")
+	for _, l := range locations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", l.Path, l.Manager, l.Key, l.Version)
+	}
+	return tw.Flush()
+}
+
+// buildScanConfig
+//
+// Returns a config file updating the scanned files.
+// As a single version key applies to a run, it holds
+// the files sharing the most common one, and lists
+// the others in comments, to be updated separately.
+func buildScanConfig(config scanConfig, locations []updater.Location) ([]byte, error) {
+	counts := make(map[string]int)
+	keys := make([]string, 0)
+	for _, l := range locations {
+		if counts[l.VersionKey()] == 0 {
+			keys = append(keys, l.VersionKey())
+		}
+		counts[l.VersionKey()]++
+	}
+
+	// The default keys win ties
+	slices.SortStableFunc(keys, func(a, b string) int {
+		switch {
+		case counts[a] != counts[b]:
+			return counts[b] - counts[a]
+		case a == "" || b == "":
+			return len(a) - len(b)
+		default:
+			return strings.Compare(a, b)
+		}
+	})
+	config.VersionKey = keys[0]
+
+	others := make([]string, 0)
+	for _, l := range locations {
+		filePath := l.Manager + ":" + l.Path
+		if l.VersionKey() != config.VersionKey {
+			others = append(others, fmt.Sprintf("#   - %s (version-key: %s)", filePath, l.VersionKey()))
+			continue
+		}
+		if !slices.Contains(config.GroupVarsFilepath, filePath) {
+			config.GroupVarsFilepath = append(config.GroupVarsFilepath, filePath)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return nil, fmt.Errorf("error when writing config file: %s", err)
+	}
+
+	content := buf.Bytes()
+	if len(others) > 0 {
+		comment := append([]string{"# These files need another version key, and a separate run:"}, slices.Compact(others)...)
+		content = append(content, []byte(strings.Join(comment, "\n")+"\n")...)
+	}
+
+	return content, nil
+}
+
+func init() {
+	scanCmd.Flags().String(repoOwner, "", "The owner of the repository to scan, its group on gitlab, its workspace on bitbucket or its project key on bitbucket-server (i.e.: cguertin14, some-other-user, infra/clusters, etc.)")
+	scanCmd.Flags().String(repoName, "", "The github repository name to scan minus the user/org part (i.e.: k3s-ansible-ha, some-other-repo, etc.)")
+	scanCmd.Flags().String(repoBranch, "main", "The branch of your github repo to scan (i.e.: main)")
+	scanCmd.Flags().String(scanDir, "", "A local checkout to scan instead of the github repository.")
+	scanCmd.Flags().String(scanOutput, "", "The path of a config file to write, ready to be used with 'update --config'.")
+	addPlatformFlags(scanCmd)
+}
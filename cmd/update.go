@@ -3,6 +3,8 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/cguertin14/k3supdater/pkg/updater"
 	"github.com/cguertin14/logger"
@@ -12,12 +14,32 @@ import (
 
 const (
 	githubAccessToken string = "GITHUB_ACCESS_TOKEN"
+	vaultPassword     string = "ANSIBLE_VAULT_PASSWORD"
 	repoOwner         string = "repo-owner"
 	repoName          string = "repo-name"
 	repoBranch        string = "repo-branch"
 	groupVarsFilepath string = "group-vars-filepath"
+	inventories       string = "inventory"
+	versionKey        string = "version-key"
 	releaseRepoOwner  string = "release-repo-owner"
 	releaseRepoName   string = "release-repo-name"
+	releaseSource     string = "release-source"
+	channel           string = "channel"
+	channelURL        string = "channel-url"
+	allowUnverified   string = "allow-unverified-channel-release"
+	updatePolicy      string = "update-policy"
+	versionConstraint string = "version-constraint"
+	stepwise          string = "stepwise"
+	minReleaseAge     string = "min-release-age"
+	allowPrereleases  string = "allow-prereleases"
+	denyVersions      string = "deny-versions"
+	pinVersion        string = "pin-version"
+	architectures     string = "architectures"
+	requiredAssets    string = "required-assets"
+	verifyChecksums   string = "verify-checksums"
+	checksumKey       string = "checksum-key"
+	vaultPasswordFile string = "vault-password-file"
+	reviewers         string = "reviewers"
 )
 
 var (
@@ -38,6 +60,41 @@ func update(cmd *cobra.Command, args []string) (err error) {
 	if err = v.BindPFlags(cmd.Flags()); err != nil {
 		return fmt.Errorf("error when parsing flags: %s", err)
 	}
+	if err = readConfig(v); err != nil {
+		return
+	}
+
+	// Required settings, which can either come
+	// from the flags or from the config file.
+	for _, key := range []string{repoOwner, repoName} {
+		if v.GetString(key) == "" {
+			return fmt.Errorf("required flag %q not set", key)
+		}
+	}
+
+	// repository specific settings come on top
+	// of the ones that apply to every repository
+	settings, err := repositorySettings(v, v.GetString(repoOwner), v.GetString(repoName))
+	if err != nil {
+		return
+	}
+	deniedVersions := append(v.GetStringSlice(denyVersions), settings.DenyVersions...)
+	pinnedVersion := v.GetString(pinVersion)
+	if settings.PinVersion != "" {
+		pinnedVersion = settings.PinVersion
+	}
+
+	// The default file is only updated along with
+	// inventories when explicitly configured.
+	paths := v.GetStringSlice(groupVarsFilepath)
+	if len(v.GetStringSlice(inventories)) > 0 && !v.IsSet(groupVarsFilepath) {
+		paths = nil
+	}
+
+	password, err := readVaultPassword(v)
+	if err != nil {
+		return
+	}
 
 	// init logger
 	ctxLogger := logger.Initialize(logger.Config{
@@ -46,22 +103,47 @@ func update(cmd *cobra.Command, args []string) (err error) {
 	})
 	ctx = context.WithValue(ctx, logger.CtxKey, ctxLogger)
 
+	repoPlatform, err := newPlatform(v)
+	if err != nil {
+		return
+	}
+
 	// create business logic client here
 	client := updater.NewClient(ctx, updater.Dependencies{
+		Platform:    repoPlatform,
 		AccessToken: v.GetString(githubAccessToken),
 	})
 
 	if err = client.UpdateK3sRelease(ctx, updater.UpdateReleaseReq{
 		Repo: updater.Repository{
-			Owner:  v.GetString(repoOwner),
-			Name:   v.GetString(repoName),
-			Path:   v.GetString(groupVarsFilepath),
-			Branch: v.GetString(repoBranch),
+			Owner:       v.GetString(repoOwner),
+			Name:        v.GetString(repoName),
+			Paths:       paths,
+			Inventories: v.GetStringSlice(inventories),
+			Branch:      v.GetString(repoBranch),
 		},
 		ReleaseRepo: updater.Repository{
 			Owner: v.GetString(releaseRepoOwner),
 			Name:  v.GetString(releaseRepoName),
 		},
+		ReleaseSource:                 updater.ReleaseSource(v.GetString(releaseSource)),
+		Channel:                       v.GetString(channel),
+		ChannelURL:                    v.GetString(channelURL),
+		AllowUnverifiedChannelRelease: v.GetBool(allowUnverified),
+		UpdatePolicy:                  updater.UpdatePolicy(v.GetString(updatePolicy)),
+		VersionConstraint:             v.GetString(versionConstraint),
+		Stepwise:                      v.GetBool(stepwise),
+		MinReleaseAge:                 v.GetDuration(minReleaseAge),
+		AllowPrereleases:              v.GetBool(allowPrereleases),
+		DeniedVersions:                deniedVersions,
+		PinnedVersion:                 pinnedVersion,
+		Architectures:                 v.GetStringSlice(architectures),
+		RequiredAssets:                v.GetStringSlice(requiredAssets),
+		VerifyChecksums:               v.GetBool(verifyChecksums),
+		ChecksumKey:                   v.GetString(checksumKey),
+		VersionKey:                    v.GetString(versionKey),
+		VaultPassword:                 password,
+		Reviewers:                     v.GetStringSlice(reviewers),
 	}); err != nil {
 		return fmt.Errorf("error when updating k3s version: %s", err)
 	}
@@ -69,15 +151,56 @@ func update(cmd *cobra.Command, args []string) (err error) {
 	return
 }
 
+// readVaultPassword
+//
+// Returns the ansible-vault password, either from
+// the ANSIBLE_VAULT_PASSWORD environment variable,
+// or from the --vault-password-file file.
+func readVaultPassword(v *viper.Viper) (string, error) {
+	if password := v.GetString(vaultPassword); password != "" {
+		return password, nil
+	}
+
+	path := v.GetString(vaultPasswordFile)
+	if path == "" {
+		return "", nil
+	}
+
+	// Trailing new lines are not part
+	// of the password, as for ansible.
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error when reading vault password file %q: %s", path, err)
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
 func init() {
-	updateCmd.Flags().String(repoOwner, "", "The github owner of the repository (i.e.: cguertin14, some-other-user, etc.)")
+	updateCmd.Flags().String(repoOwner, "", "The owner of the repository, its group on gitlab, its workspace on bitbucket or its project key on bitbucket-server (i.e.: cguertin14, some-other-user, infra/clusters, etc.)")
 	updateCmd.Flags().String(repoName, "", "The github repository name minus the user/org part (i.e.: k3s-ansible-ha, some-other-repo, etc.)")
 	updateCmd.Flags().String(repoBranch, "main", "The branch of your github repo to edit (i.e.: main)")
-	updateCmd.Flags().String(groupVarsFilepath, "inventory/pi-cluster/group_vars/all.yml", "The path of the 'inventory/<YOUR_MACHINE>/group_vars/<YOUR_FILE>.yml' file in your github repo to edit.")
+	updateCmd.Flags().StringSlice(groupVarsFilepath, []string{"inventory/pi-cluster/group_vars/all.yml"}, "The paths of the 'inventory/<YOUR_MACHINE>/group_vars/<YOUR_FILE>.yml' files in your github repo to edit, glob patterns included (i.e.: inventory/*/group_vars/all.yml). The file type is detected, or set with a prefix (i.e.: plan:manifests/k3s-upgrade.yml). Every file is updated in the same PR.")
+	updateCmd.Flags().StringSlice(inventories, []string{}, "The ansible inventory directories of your github repo (i.e.: inventory/pi-cluster) in which every group_vars and host_vars definition of the version is updated to the same version. Conflicting definitions are reported in the PR.")
+	updateCmd.Flags().String(versionKey, "", "The path of the k3s version in the group_vars file, either dotted (i.e.: k3s.version) or JSONPath-like (i.e.: $.k3s['version']), or the variable holding it in other files. Defaults to "+updater.DefaultVersionKey+" in group_vars files, "+updater.DefaultTerraformVariable+" in terraform files, and INSTALL_K3S_VERSION or K3S_VERSION in env files.")
 	updateCmd.Flags().String(releaseRepoOwner, "k3s-io", "The github owner of the release repository (i.e.: k3s-io, some-other-org, etc.).")
 	updateCmd.Flags().String(releaseRepoName, "k3s", "The github release repository name minus the user/org part (i.e.: k3s, some-other-repo, etc.)")
-
-	// Required flags
-	updateCmd.MarkFlagRequired(repoOwner)
-	updateCmd.MarkFlagRequired(repoName)
+	updateCmd.Flags().String(releaseSource, string(updater.ReleaseSourceReleases), "Where to find versions on the release repository (i.e.: releases, tags, latest).")
+	updateCmd.Flags().String(channel, "", "The k3s release channel to track (i.e.: stable, latest, v1.30, etc.). When empty, the newest github release is used.")
+	updateCmd.Flags().String(channelURL, updater.DefaultChannelURL, "The location of the k3s channel document, either an http(s) URL or a local file.")
+	updateCmd.Flags().Bool(allowUnverified, false, "Propose the version of the channel when its github release is missing, even though its assets and release age can't be verified.")
+	updateCmd.Flags().String(updatePolicy, string(updater.UpdatePolicyMajor), "How far from the current version an update can go (i.e.: patch, minor, major).")
+	updateCmd.Flags().String(versionConstraint, "", "A semver range the proposed version must satisfy (i.e.: \">=v1.29.0 <v1.31.0\").")
+	updateCmd.Flags().Bool(stepwise, false, "Only propose the next minor version when the current one is more than one minor behind.")
+	updateCmd.Flags().Duration(minReleaseAge, 0, "How long a release must have been published before being proposed (i.e.: 72h).")
+	updateCmd.Flags().Bool(allowPrereleases, false, "Allow release candidates and other pre-releases to be proposed (i.e.: for a staging inventory).")
+	updateCmd.Flags().StringArray(denyVersions, []string{}, "A version or semver range that must never be proposed (i.e.: v1.30.2, \">=v1.30.0 <v1.30.3\"). Can be repeated.")
+	updateCmd.Flags().String(pinVersion, "", "The highest version that can be proposed (i.e.: v1.30.9).")
+	updateCmd.Flags().StringSlice(architectures, []string{"amd64", "arm64", "arm"}, "The architectures of the cluster nodes, whose k3s binary and checksums must be attached to a release (i.e.: amd64,arm64,arm).")
+	updateCmd.Flags().Bool(verifyChecksums, false, "Verify the k3s binary of every architecture against the release checksums before proposing it.")
+	updateCmd.Flags().String(checksumKey, "", "The group_vars key to update with the verified checksum of the first architecture (i.e.: k3s_checksum).")
+	updateCmd.Flags().String(vaultPasswordFile, "", "The file holding the password of the files encrypted with ansible-vault, unless the ANSIBLE_VAULT_PASSWORD environment variable is set. Encrypted files are encrypted again once updated.")
+	updateCmd.Flags().StringSlice(reviewers, []string{}, "The users requested to review the pull request: usernames, or account ids or uuids on bitbucket cloud (i.e.: cguertin14,some-other-user).")
+	updateCmd.Flags().StringSlice(requiredAssets, []string{}, "Asset name patterns a release must have, instead of the ones derived from the architectures (i.e.: k3s-arm64,k3s-airgap-images-arm64.*).")
+	addPlatformFlags(updateCmd)
 }
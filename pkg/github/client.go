@@ -12,12 +12,25 @@ type CommonRequest struct {
 	Repo  string
 }
 
+type ListRequest struct {
+	Owner string
+	Repo  string
+	Page  int
+}
+
 type CreatePRRequest struct {
 	Owner string
 	Repo  string
 	*github.NewPullRequest
 }
 
+type RequestReviewersRequest struct {
+	Owner  string
+	Repo   string
+	Number int
+	github.ReviewersRequest
+}
+
 type UpdateFileRequest struct {
 	Owner    string
 	Repo     string
@@ -32,12 +45,25 @@ type GetRepositoryContentsRequest struct {
 	Branch string
 }
 
+type GetTreeRequest struct {
+	Owner     string
+	Repo      string
+	SHA       string
+	Recursive bool
+}
+
 type GetReleaseRequest struct {
 	Owner     string
 	Repo      string
 	ReleaseID int64
 }
 
+type GetReleaseByTagRequest struct {
+	Owner string
+	Repo  string
+	Tag   string
+}
+
 type GetBranchRequest struct {
 	Owner      string
 	Repo       string
@@ -50,16 +76,74 @@ type CreateBranchRequest struct {
 	*github.Reference
 }
 
+type GetCommitRequest struct {
+	Owner string
+	Repo  string
+	SHA   string
+}
+
+type CreateBlobRequest struct {
+	Owner string
+	Repo  string
+	*github.Blob
+}
+
+type CreateTreeRequest struct {
+	Owner    string
+	Repo     string
+	BaseTree string
+	Entries  []*github.TreeEntry
+}
+
+type CreateCommitRequest struct {
+	Owner string
+	Repo  string
+	*github.Commit
+}
+
+type UpdateRefRequest struct {
+	Owner string
+	Repo  string
+	Force bool
+	*github.Reference
+}
+
 type Client interface {
 	// GetRepositoryContents
 	//
 	// Fetches a specific file/folder in a github repo on a given branch.
 	GetRepositoryContents(ctx context.Context, req GetRepositoryContentsRequest) (fileContent *github.RepositoryContent, directoryContent []*github.RepositoryContent, resp *github.Response, err error)
 
+	// GetTree
+	//
+	// Returns the git tree of a given commit or
+	// branch, including subtrees when recursive.
+	GetTree(ctx context.Context, req GetTreeRequest) (*github.Tree, *github.Response, error)
+
 	// GetRepositoryReleases
 	//
-	// Get all releases from a given repository.
-	GetRepositoryReleases(ctx context.Context, req CommonRequest) ([]*github.RepositoryRelease, *github.Response, error)
+	// Get a page of releases from a given repository.
+	// The next page to fetch, if any, is available
+	// in the returned response.
+	GetRepositoryReleases(ctx context.Context, req ListRequest) ([]*github.RepositoryRelease, *github.Response, error)
+
+	// GetLatestRelease
+	//
+	// Returns the latest published full release
+	// of a given repository.
+	GetLatestRelease(ctx context.Context, req CommonRequest) (*github.RepositoryRelease, *github.Response, error)
+
+	// GetRepositoryTags
+	//
+	// Get a page of tags from a given repository.
+	// The next page to fetch, if any, is available
+	// in the returned response.
+	GetRepositoryTags(ctx context.Context, req ListRequest) ([]*github.RepositoryTag, *github.Response, error)
+
+	// GetReleaseByTag
+	//
+	// Returns the release published for a given tag.
+	GetReleaseByTag(ctx context.Context, req GetReleaseByTagRequest) (*github.RepositoryRelease, *github.Response, error)
 
 	// GetBranch
 	//
@@ -71,6 +155,12 @@ type Client interface {
 	// Creates a Pull Requests on a given repository.
 	CreatePullRequest(ctx context.Context, req CreatePRRequest) (*github.PullRequest, *github.Response, error)
 
+	// RequestReviewers
+	//
+	// Requests reviews of a given pull request
+	// from users or teams.
+	RequestReviewers(ctx context.Context, req RequestReviewersRequest) (*github.PullRequest, *github.Response, error)
+
 	// CreateBranch
 	//
 	// Creates a branch on a given repository.
@@ -80,13 +170,55 @@ type Client interface {
 	//
 	// Updates a file in a given repo with new content.
 	UpdateFile(ctx context.Context, req UpdateFileRequest) (*github.RepositoryContentResponse, *github.Response, error)
+
+	// GetCommit
+	//
+	// Returns a git commit, along with its tree, given its SHA.
+	GetCommit(ctx context.Context, req GetCommitRequest) (*github.Commit, *github.Response, error)
+
+	// CreateBlob
+	//
+	// Stores new file content in a given repo, without
+	// adding it to any tree or branch.
+	CreateBlob(ctx context.Context, req CreateBlobRequest) (*github.Blob, *github.Response, error)
+
+	// CreateTree
+	//
+	// Creates a git tree in a given repo, made of a
+	// base tree with some of its entries replaced.
+	CreateTree(ctx context.Context, req CreateTreeRequest) (*github.Tree, *github.Response, error)
+
+	// CreateCommit
+	//
+	// Creates a git commit in a given repo, without
+	// updating any branch.
+	CreateCommit(ctx context.Context, req CreateCommitRequest) (*github.Commit, *github.Response, error)
+
+	// UpdateRef
+	//
+	// Points a reference (i.e.: a branch) of a given
+	// repo to another commit. Unless forced, the new
+	// commit must be a descendant of the current one.
+	UpdateRef(ctx context.Context, req UpdateRefRequest) (*github.Reference, *github.Response, error)
 }
 
+// listPerPage is the maximum page
+// size allowed by the github API.
+const listPerPage = 100
+
 type ClientSet struct {
 	github *github.Client
 }
 
 func NewClient(ctx context.Context, accessToken string) *ClientSet {
+	// Public repositories, like the release
+	// one, can be read with no access token.
+	if accessToken == "" {
+		return &ClientSet{
+			github: github.NewClient(nil),
+		}
+	}
+
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: accessToken},
 	)
@@ -101,12 +233,44 @@ func NewClient(ctx context.Context, accessToken string) *ClientSet {
 // implements Client interface
 var _ Client = &ClientSet{}
 
-func (c *ClientSet) GetRepositoryReleases(ctx context.Context, req CommonRequest) ([]*github.RepositoryRelease, *github.Response, error) {
+func (c *ClientSet) GetRepositoryReleases(ctx context.Context, req ListRequest) ([]*github.RepositoryRelease, *github.Response, error) {
 	return c.github.Repositories.ListReleases(
 		ctx,
 		req.Owner,
 		req.Repo,
-		&github.ListOptions{},
+		&github.ListOptions{
+			Page:    req.Page,
+			PerPage: listPerPage,
+		},
+	)
+}
+
+func (c *ClientSet) GetLatestRelease(ctx context.Context, req CommonRequest) (*github.RepositoryRelease, *github.Response, error) {
+	return c.github.Repositories.GetLatestRelease(
+		ctx,
+		req.Owner,
+		req.Repo,
+	)
+}
+
+func (c *ClientSet) GetRepositoryTags(ctx context.Context, req ListRequest) ([]*github.RepositoryTag, *github.Response, error) {
+	return c.github.Repositories.ListTags(
+		ctx,
+		req.Owner,
+		req.Repo,
+		&github.ListOptions{
+			Page:    req.Page,
+			PerPage: listPerPage,
+		},
+	)
+}
+
+func (c *ClientSet) GetReleaseByTag(ctx context.Context, req GetReleaseByTagRequest) (*github.RepositoryRelease, *github.Response, error) {
+	return c.github.Repositories.GetReleaseByTag(
+		ctx,
+		req.Owner,
+		req.Repo,
+		req.Tag,
 	)
 }
 
@@ -122,6 +286,16 @@ func (c *ClientSet) GetRepositoryContents(ctx context.Context, req GetRepository
 	)
 }
 
+func (c *ClientSet) GetTree(ctx context.Context, req GetTreeRequest) (*github.Tree, *github.Response, error) {
+	return c.github.Git.GetTree(
+		ctx,
+		req.Owner,
+		req.Repo,
+		req.SHA,
+		req.Recursive,
+	)
+}
+
 func (c *ClientSet) CreatePullRequest(ctx context.Context, req CreatePRRequest) (*github.PullRequest, *github.Response, error) {
 	return c.github.PullRequests.Create(
 		ctx,
@@ -131,6 +305,16 @@ func (c *ClientSet) CreatePullRequest(ctx context.Context, req CreatePRRequest)
 	)
 }
 
+func (c *ClientSet) RequestReviewers(ctx context.Context, req RequestReviewersRequest) (*github.PullRequest, *github.Response, error) {
+	return c.github.PullRequests.RequestReviewers(
+		ctx,
+		req.Owner,
+		req.Repo,
+		req.Number,
+		req.ReviewersRequest,
+	)
+}
+
 func (c *ClientSet) GetBranch(ctx context.Context, req GetBranchRequest) (*github.Reference, *github.Response, error) {
 	return c.github.Git.GetRef(
 		ctx,
@@ -158,3 +342,51 @@ func (c *ClientSet) UpdateFile(ctx context.Context, req UpdateFileRequest) (*git
 		req.RepositoryContentFileOptions,
 	)
 }
+
+func (c *ClientSet) GetCommit(ctx context.Context, req GetCommitRequest) (*github.Commit, *github.Response, error) {
+	return c.github.Git.GetCommit(
+		ctx,
+		req.Owner,
+		req.Repo,
+		req.SHA,
+	)
+}
+
+func (c *ClientSet) CreateBlob(ctx context.Context, req CreateBlobRequest) (*github.Blob, *github.Response, error) {
+	return c.github.Git.CreateBlob(
+		ctx,
+		req.Owner,
+		req.Repo,
+		req.Blob,
+	)
+}
+
+func (c *ClientSet) CreateTree(ctx context.Context, req CreateTreeRequest) (*github.Tree, *github.Response, error) {
+	return c.github.Git.CreateTree(
+		ctx,
+		req.Owner,
+		req.Repo,
+		req.BaseTree,
+		req.Entries,
+	)
+}
+
+func (c *ClientSet) CreateCommit(ctx context.Context, req CreateCommitRequest) (*github.Commit, *github.Response, error) {
+	return c.github.Git.CreateCommit(
+		ctx,
+		req.Owner,
+		req.Repo,
+		req.Commit,
+		&github.CreateCommitOptions{},
+	)
+}
+
+func (c *ClientSet) UpdateRef(ctx context.Context, req UpdateRefRequest) (*github.Reference, *github.Response, error) {
+	return c.github.Git.UpdateRef(
+		ctx,
+		req.Owner,
+		req.Repo,
+		req.Reference,
+		req.Force,
+	)
+}
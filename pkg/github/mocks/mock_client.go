@@ -36,6 +36,22 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// CreateBlob mocks base method.
+func (m *MockClient) CreateBlob(arg0 context.Context, arg1 legacy.CreateBlobRequest) (*github.Blob, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBlob", arg0, arg1)
+	ret0, _ := ret[0].(*github.Blob)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateBlob indicates an expected call of CreateBlob.
+func (mr *MockClientMockRecorder) CreateBlob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBlob", reflect.TypeOf((*MockClient)(nil).CreateBlob), arg0, arg1)
+}
+
 // CreateBranch mocks base method.
 func (m *MockClient) CreateBranch(arg0 context.Context, arg1 legacy.CreateBranchRequest) (*github.Reference, *github.Response, error) {
 	m.ctrl.T.Helper()
@@ -52,6 +68,22 @@ func (mr *MockClientMockRecorder) CreateBranch(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBranch", reflect.TypeOf((*MockClient)(nil).CreateBranch), arg0, arg1)
 }
 
+// CreateCommit mocks base method.
+func (m *MockClient) CreateCommit(arg0 context.Context, arg1 legacy.CreateCommitRequest) (*github.Commit, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommit", arg0, arg1)
+	ret0, _ := ret[0].(*github.Commit)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateCommit indicates an expected call of CreateCommit.
+func (mr *MockClientMockRecorder) CreateCommit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommit", reflect.TypeOf((*MockClient)(nil).CreateCommit), arg0, arg1)
+}
+
 // CreatePullRequest mocks base method.
 func (m *MockClient) CreatePullRequest(arg0 context.Context, arg1 legacy.CreatePRRequest) (*github.PullRequest, *github.Response, error) {
 	m.ctrl.T.Helper()
@@ -68,6 +100,22 @@ func (mr *MockClientMockRecorder) CreatePullRequest(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePullRequest", reflect.TypeOf((*MockClient)(nil).CreatePullRequest), arg0, arg1)
 }
 
+// CreateTree mocks base method.
+func (m *MockClient) CreateTree(arg0 context.Context, arg1 legacy.CreateTreeRequest) (*github.Tree, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTree", arg0, arg1)
+	ret0, _ := ret[0].(*github.Tree)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateTree indicates an expected call of CreateTree.
+func (mr *MockClientMockRecorder) CreateTree(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTree", reflect.TypeOf((*MockClient)(nil).CreateTree), arg0, arg1)
+}
+
 // GetBranch mocks base method.
 func (m *MockClient) GetBranch(arg0 context.Context, arg1 legacy.GetBranchRequest) (*github.Reference, *github.Response, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +132,54 @@ func (mr *MockClientMockRecorder) GetBranch(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBranch", reflect.TypeOf((*MockClient)(nil).GetBranch), arg0, arg1)
 }
 
+// GetCommit mocks base method.
+func (m *MockClient) GetCommit(arg0 context.Context, arg1 legacy.GetCommitRequest) (*github.Commit, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommit", arg0, arg1)
+	ret0, _ := ret[0].(*github.Commit)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCommit indicates an expected call of GetCommit.
+func (mr *MockClientMockRecorder) GetCommit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommit", reflect.TypeOf((*MockClient)(nil).GetCommit), arg0, arg1)
+}
+
+// GetLatestRelease mocks base method.
+func (m *MockClient) GetLatestRelease(arg0 context.Context, arg1 legacy.CommonRequest) (*github.RepositoryRelease, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestRelease", arg0, arg1)
+	ret0, _ := ret[0].(*github.RepositoryRelease)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLatestRelease indicates an expected call of GetLatestRelease.
+func (mr *MockClientMockRecorder) GetLatestRelease(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestRelease", reflect.TypeOf((*MockClient)(nil).GetLatestRelease), arg0, arg1)
+}
+
+// GetReleaseByTag mocks base method.
+func (m *MockClient) GetReleaseByTag(arg0 context.Context, arg1 legacy.GetReleaseByTagRequest) (*github.RepositoryRelease, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReleaseByTag", arg0, arg1)
+	ret0, _ := ret[0].(*github.RepositoryRelease)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetReleaseByTag indicates an expected call of GetReleaseByTag.
+func (mr *MockClientMockRecorder) GetReleaseByTag(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReleaseByTag", reflect.TypeOf((*MockClient)(nil).GetReleaseByTag), arg0, arg1)
+}
+
 // GetRepositoryContents mocks base method.
 func (m *MockClient) GetRepositoryContents(arg0 context.Context, arg1 legacy.GetRepositoryContentsRequest) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
 	m.ctrl.T.Helper()
@@ -102,7 +198,7 @@ func (mr *MockClientMockRecorder) GetRepositoryContents(arg0, arg1 interface{})
 }
 
 // GetRepositoryReleases mocks base method.
-func (m *MockClient) GetRepositoryReleases(arg0 context.Context, arg1 legacy.CommonRequest) ([]*github.RepositoryRelease, *github.Response, error) {
+func (m *MockClient) GetRepositoryReleases(arg0 context.Context, arg1 legacy.ListRequest) ([]*github.RepositoryRelease, *github.Response, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRepositoryReleases", arg0, arg1)
 	ret0, _ := ret[0].([]*github.RepositoryRelease)
@@ -117,6 +213,54 @@ func (mr *MockClientMockRecorder) GetRepositoryReleases(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepositoryReleases", reflect.TypeOf((*MockClient)(nil).GetRepositoryReleases), arg0, arg1)
 }
 
+// GetRepositoryTags mocks base method.
+func (m *MockClient) GetRepositoryTags(arg0 context.Context, arg1 legacy.ListRequest) ([]*github.RepositoryTag, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRepositoryTags", arg0, arg1)
+	ret0, _ := ret[0].([]*github.RepositoryTag)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRepositoryTags indicates an expected call of GetRepositoryTags.
+func (mr *MockClientMockRecorder) GetRepositoryTags(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepositoryTags", reflect.TypeOf((*MockClient)(nil).GetRepositoryTags), arg0, arg1)
+}
+
+// GetTree mocks base method.
+func (m *MockClient) GetTree(arg0 context.Context, arg1 legacy.GetTreeRequest) (*github.Tree, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTree", arg0, arg1)
+	ret0, _ := ret[0].(*github.Tree)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTree indicates an expected call of GetTree.
+func (mr *MockClientMockRecorder) GetTree(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTree", reflect.TypeOf((*MockClient)(nil).GetTree), arg0, arg1)
+}
+
+// RequestReviewers mocks base method.
+func (m *MockClient) RequestReviewers(arg0 context.Context, arg1 legacy.RequestReviewersRequest) (*github.PullRequest, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestReviewers", arg0, arg1)
+	ret0, _ := ret[0].(*github.PullRequest)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// RequestReviewers indicates an expected call of RequestReviewers.
+func (mr *MockClientMockRecorder) RequestReviewers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestReviewers", reflect.TypeOf((*MockClient)(nil).RequestReviewers), arg0, arg1)
+}
+
 // UpdateFile mocks base method.
 func (m *MockClient) UpdateFile(arg0 context.Context, arg1 legacy.UpdateFileRequest) (*github.RepositoryContentResponse, *github.Response, error) {
 	m.ctrl.T.Helper()
@@ -132,3 +276,19 @@ func (mr *MockClientMockRecorder) UpdateFile(arg0, arg1 interface{}) *gomock.Cal
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFile", reflect.TypeOf((*MockClient)(nil).UpdateFile), arg0, arg1)
 }
+
+// UpdateRef mocks base method.
+func (m *MockClient) UpdateRef(arg0 context.Context, arg1 legacy.UpdateRefRequest) (*github.Reference, *github.Response, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRef", arg0, arg1)
+	ret0, _ := ret[0].(*github.Reference)
+	ret1, _ := ret[1].(*github.Response)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateRef indicates an expected call of UpdateRef.
+func (mr *MockClientMockRecorder) UpdateRef(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRef", reflect.TypeOf((*MockClient)(nil).UpdateRef), arg0, arg1)
+}
@@ -0,0 +1,210 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultBitbucketURL is the url of the bitbucket
+// cloud API, used unless another one is set.
+const DefaultBitbucketURL string = "https://api.bitbucket.org"
+
+const (
+	// bitbucketPerPage is the maximum page
+	// size allowed by the bitbucket cloud API.
+	bitbucketPerPage = 100
+
+	// bitbucketMaxDepth is how deep directories
+	// are listed when listing the files of a branch.
+	bitbucketMaxDepth = 32
+)
+
+// BitbucketCloud is the bitbucket.org
+// platform, through its REST API (2.0).
+type BitbucketCloud struct {
+	rest *restClient
+}
+
+// NewBitbucketCloud
+//
+// Returns a bitbucket cloud platform, authenticated
+// with a repository, project or workspace access
+// token, or with an app password when a username
+// is set. The base url is the url of the API.
+func NewBitbucketCloud(baseURL, username, accessToken string, httpClient *http.Client) *BitbucketCloud {
+	if baseURL == "" {
+		baseURL = DefaultBitbucketURL
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &BitbucketCloud{
+		rest: &restClient{
+			baseURL:    strings.TrimSuffix(baseURL, "/") + "/2.0",
+			httpClient: httpClient,
+			authorize:  bitbucketAuthorize(username, accessToken),
+		},
+	}
+}
+
+// bitbucketAuthorize
+//
+// Returns how requests are authenticated on
+// bitbucket: with basic auth when a username is
+// set, or with the token as a bearer otherwise.
+func bitbucketAuthorize(username, accessToken string) func(r *http.Request) {
+	return func(r *http.Request) {
+		switch {
+		case accessToken == "":
+		case username != "":
+			r.SetBasicAuth(username, accessToken)
+		default:
+			r.Header.Set("Authorization", "Bearer "+accessToken)
+		}
+	}
+}
+
+// isBranchExistsError
+//
+// Returns whether a bitbucket error is about
+// a branch which already exists, which both
+// APIs report with a 400 or a 409 status.
+func isBranchExistsError(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+
+	// i.e.: {"type":"error","error":{"message":"BRANCH_ALREADY_EXISTS"}}
+	message := strings.ToLower(httpErr.Message)
+	return httpErr.StatusCode == http.StatusConflict ||
+		httpErr.StatusCode == http.StatusBadRequest && (strings.Contains(message, "already_exists") || strings.Contains(message, "already exists"))
+}
+
+// Make sure BitbucketCloud struct
+// implements Platform interface
+var _ Platform = &BitbucketCloud{}
+
+// repoPath
+//
+// Returns the API path of a repository,
+// where the owner is the workspace.
+func (b *BitbucketCloud) repoPath(repo Repository) string {
+	return "/repositories/" + url.PathEscape(repo.Owner) + "/" + url.PathEscape(repo.Name)
+}
+
+func (b *BitbucketCloud) GetFile(ctx context.Context, req GetFileRequest) ([]byte, error) {
+	return b.rest.raw(ctx,
+		b.repoPath(req.Repository)+"/src/"+url.PathEscape(req.Branch)+"/"+escapePath(req.Path),
+		nil,
+	)
+}
+
+func (b *BitbucketCloud) ListFiles(ctx context.Context, req ListFilesRequest) ([]string, error) {
+	paths := make([]string, 0)
+	query := url.Values{
+		"max_depth": {strconv.Itoa(bitbucketMaxDepth)},
+		"pagelen":   {strconv.Itoa(bitbucketPerPage)},
+	}
+	for query != nil {
+		var page struct {
+			Values []struct {
+				Path string `json:"path"`
+				Type string `json:"type"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+		err := b.rest.do(ctx, http.MethodGet, b.repoPath(req.Repository)+"/src/"+url.PathEscape(req.Branch)+"/", query, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range page.Values {
+			if entry.Type == "commit_file" {
+				paths = append(paths, entry.Path)
+			}
+		}
+
+		// The next page is a url of the same listing,
+		// of which only the query is kept so that the
+		// base url remains the configured one.
+		query = nil
+		if page.Next != "" {
+			next, err := url.Parse(page.Next)
+			if err != nil {
+				return nil, fmt.Errorf("error when parsing next page %q: %s", page.Next, err)
+			}
+			query = next.Query()
+		}
+	}
+
+	return paths, nil
+}
+
+func (b *BitbucketCloud) CreateBranch(ctx context.Context, req CreateBranchRequest) error {
+	err := b.rest.do(ctx, http.MethodPost, b.repoPath(req.Repository)+"/refs/branches", nil, map[string]any{
+		"name": req.Branch,
+		"target": map[string]string{
+			// A branch name is accepted as target
+			"hash": req.Base,
+		},
+	}, nil)
+	if isBranchExistsError(err) {
+		return errors.Join(ErrBranchExists, err)
+	}
+
+	return err
+}
+
+// Commit
+//
+// Commits every file at once through the src
+// endpoint, where form fields named after a
+// path hold the new content of the file.
+func (b *BitbucketCloud) Commit(ctx context.Context, req CommitRequest) error {
+	fields := form{
+		"branch":  req.Branch,
+		"message": req.Message,
+		"author":  fmt.Sprintf("%s <%s>", req.Author.Name, req.Author.Email),
+	}
+	for _, f := range req.Files {
+		fields[f.Path] = string(f.Content)
+	}
+
+	return b.rest.do(ctx, http.MethodPost, b.repoPath(req.Repository)+"/src", nil, fields, nil)
+}
+
+// CreatePullRequest
+//
+// Opens a pull request, whose branch is closed once
+// it is merged. Reviewers are either account ids or
+// uuids (i.e.: {d301aafa-d676-4ee0-88be-962be7417567}),
+// since usernames can't be used on bitbucket cloud.
+func (b *BitbucketCloud) CreatePullRequest(ctx context.Context, req CreatePullRequestRequest) error {
+	reviewers := make([]map[string]string, 0, len(req.Reviewers))
+	for _, reviewer := range req.Reviewers {
+		if strings.HasPrefix(reviewer, "{") {
+			reviewers = append(reviewers, map[string]string{"uuid": reviewer})
+		} else {
+			reviewers = append(reviewers, map[string]string{"account_id": reviewer})
+		}
+	}
+
+	branch := func(name string) map[string]any {
+		return map[string]any{"branch": map[string]string{"name": name}}
+	}
+	return b.rest.do(ctx, http.MethodPost, b.repoPath(req.Repository)+"/pullrequests", nil, map[string]any{
+		"title":               req.Title,
+		"description":         req.Body,
+		"source":              branch(req.Head),
+		"destination":         branch(req.Base),
+		"reviewers":           reviewers,
+		"close_source_branch": true,
+	}, nil)
+}
@@ -0,0 +1,219 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// bitbucketServerPerPage is the page size of
+// file listings on bitbucket server.
+const bitbucketServerPerPage = 1000
+
+// BitbucketServer is a bitbucket server, or data
+// center, instance, through its REST API (1.0).
+type BitbucketServer struct {
+	rest *restClient
+
+	// branches calls the branch utils API, which
+	// deletes branches, unlike the core one.
+	branches *restClient
+}
+
+// NewBitbucketServer
+//
+// Returns a bitbucket server (or data center) platform,
+// authenticated with an HTTP access token, or with a
+// password when a username is set. The base url is the
+// url of the instance, which is required.
+func NewBitbucketServer(baseURL, username, accessToken string, httpClient *http.Client) (*BitbucketServer, error) {
+	if baseURL == "" {
+		return nil, errors.New("the url of the bitbucket server instance is required")
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &BitbucketServer{
+		rest: &restClient{
+			baseURL:    baseURL + "/rest/api/1.0",
+			httpClient: httpClient,
+			authorize:  bitbucketAuthorize(username, accessToken),
+		},
+		branches: &restClient{
+			baseURL:    baseURL + "/rest/branch-utils/1.0",
+			httpClient: httpClient,
+			authorize:  bitbucketAuthorize(username, accessToken),
+		},
+	}, nil
+}
+
+// Make sure BitbucketServer struct
+// implements Platform interface
+var _ Platform = &BitbucketServer{}
+
+// repoPath
+//
+// Returns the API path of a repository,
+// where the owner is the project key.
+func (b *BitbucketServer) repoPath(repo Repository) string {
+	return "/projects/" + url.PathEscape(repo.Owner) + "/repos/" + url.PathEscape(repo.Name)
+}
+
+// branchRef
+//
+// Returns the full reference of a branch.
+func branchRef(branch string) string {
+	return "refs/heads/" + branch
+}
+
+func (b *BitbucketServer) GetFile(ctx context.Context, req GetFileRequest) ([]byte, error) {
+	return b.rest.raw(ctx,
+		b.repoPath(req.Repository)+"/raw/"+escapePath(req.Path),
+		url.Values{"at": {branchRef(req.Branch)}},
+	)
+}
+
+func (b *BitbucketServer) ListFiles(ctx context.Context, req ListFilesRequest) ([]string, error) {
+	paths := make([]string, 0)
+	for start, last := 0, false; !last; {
+		var page struct {
+			Values        []string `json:"values"`
+			IsLastPage    bool     `json:"isLastPage"`
+			NextPageStart int      `json:"nextPageStart"`
+		}
+		err := b.rest.do(ctx, http.MethodGet, b.repoPath(req.Repository)+"/files", url.Values{
+			"at":    {branchRef(req.Branch)},
+			"limit": {strconv.Itoa(bitbucketServerPerPage)},
+			"start": {strconv.Itoa(start)},
+		}, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, page.Values...)
+		start, last = page.NextPageStart, page.IsLastPage
+	}
+
+	return paths, nil
+}
+
+func (b *BitbucketServer) CreateBranch(ctx context.Context, req CreateBranchRequest) error {
+	err := b.rest.do(ctx, http.MethodPost, b.repoPath(req.Repository)+"/branches", nil, map[string]string{
+		"name":       req.Branch,
+		"startPoint": branchRef(req.Base),
+	}, nil)
+	if isBranchExistsError(err) {
+		return errors.Join(ErrBranchExists, err)
+	}
+
+	return err
+}
+
+// Commit
+//
+// Commits files one by one through the browse
+// endpoint, since bitbucket server can't commit
+// several files at once. Each commit is made on
+// top of the previous one, and authored by the
+// owner of the access token. The branch, created
+// for these commits, is deleted if any of them
+// fails, so that it is never left half updated.
+func (b *BitbucketServer) Commit(ctx context.Context, req CommitRequest) error {
+	err := b.commitFiles(ctx, req)
+	if err == nil {
+		return nil
+	}
+
+	if deleteErr := b.deleteBranch(ctx, req.Repository, req.Branch); deleteErr != nil {
+		return fmt.Errorf("%s, and branch %q could not be deleted: %s", err, req.Branch, deleteErr)
+	}
+
+	return err
+}
+
+// commitFiles
+//
+// Commits files one by one on top
+// of the head of a given branch.
+func (b *BitbucketServer) commitFiles(ctx context.Context, req CommitRequest) error {
+	var head struct {
+		Values []struct {
+			ID string `json:"id"`
+		} `json:"values"`
+	}
+	err := b.rest.do(ctx, http.MethodGet, b.repoPath(req.Repository)+"/commits", url.Values{
+		"until": {branchRef(req.Branch)},
+		"limit": {"1"},
+	}, nil, &head)
+	if err != nil {
+		return fmt.Errorf("error when fetching branch %q: %s", req.Branch, err)
+	}
+	if len(head.Values) == 0 {
+		return fmt.Errorf("error when fetching branch %q: no commits found", req.Branch)
+	}
+
+	parent := head.Values[0].ID
+	for _, f := range req.Files {
+		var commit struct {
+			ID string `json:"id"`
+		}
+		err := b.rest.do(ctx, http.MethodPut, b.repoPath(req.Repository)+"/browse/"+escapePath(f.Path), nil, form{
+			"branch":         req.Branch,
+			"message":        req.Message,
+			"content":        string(f.Content),
+			"sourceCommitId": parent,
+		}, &commit)
+		if err != nil {
+			return fmt.Errorf("error when updating file %q: %s", f.Path, err)
+		}
+		parent = commit.ID
+	}
+
+	return nil
+}
+
+// deleteBranch
+//
+// Deletes a branch through the branch utils API.
+func (b *BitbucketServer) deleteBranch(ctx context.Context, repo Repository, branch string) error {
+	return b.branches.do(ctx, http.MethodDelete, b.repoPath(repo)+"/branches", nil, map[string]any{
+		"name":   branchRef(branch),
+		"dryRun": false,
+	}, nil)
+}
+
+// CreatePullRequest
+//
+// Opens a pull request, of which
+// reviewers are usernames.
+func (b *BitbucketServer) CreatePullRequest(ctx context.Context, req CreatePullRequestRequest) error {
+	reviewers := make([]map[string]any, 0, len(req.Reviewers))
+	for _, reviewer := range req.Reviewers {
+		reviewers = append(reviewers, map[string]any{
+			"user": map[string]string{"name": reviewer},
+		})
+	}
+
+	ref := func(branch string) map[string]any {
+		return map[string]any{
+			"id": branchRef(branch),
+			"repository": map[string]any{
+				"slug":    req.Name,
+				"project": map[string]string{"key": req.Owner},
+			},
+		}
+	}
+	return b.rest.do(ctx, http.MethodPost, b.repoPath(req.Repository)+"/pull-requests", nil, map[string]any{
+		"title":       req.Title,
+		"description": req.Body,
+		"fromRef":     ref(req.Head),
+		"toRef":       ref(req.Base),
+		"reviewers":   reviewers,
+	}, nil)
+}
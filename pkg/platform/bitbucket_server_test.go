@@ -0,0 +1,233 @@
+//go:build test
+// +build test
+
+package platform
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var bitbucketServerRepo = Repository{Owner: "INFRA", Name: "k3s-ansible"}
+
+func TestNewBitbucketServer(t *testing.T) {
+	if _, err := NewBitbucketServer("", "", "some token", nil); err == nil {
+		t.Fatal("expected an error without url")
+	}
+}
+
+func TestBitbucketServerGetFile(t *testing.T) {
+	cases := map[string]struct {
+		path  string
+		token string
+
+		expected    string
+		expectError bool
+	}{
+		"success case with no error": {
+			path:     "inventory/prod/group_vars/all.yml",
+			token:    "some token",
+			expected: "k3s_release_version: v1.28.5+k3s1\n",
+		},
+		"error case with missing file": {
+			path:        "inventory/lab/group_vars/all.yml",
+			token:       "some token",
+			expectError: true,
+		},
+		"error case with wrong token": {
+			path:        "inventory/prod/group_vars/all.yml",
+			token:       "some other token",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newReplayServer(t, "bitbucket/server/get_file.json")
+			bitbucket, err := NewBitbucketServer(server.URL, "", c.token, server.Client())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			content, err := bitbucket.GetFile(context.Background(), GetFileRequest{
+				Repository: bitbucketServerRepo,
+				Path:       c.path,
+				Branch:     "main",
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(content) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, content)
+			}
+		})
+	}
+}
+
+func TestBitbucketServerListFiles(t *testing.T) {
+	server, _ := newReplayServer(t, "bitbucket/server/list_files.json")
+	bitbucket, _ := NewBitbucketServer(server.URL, "", "some token", server.Client())
+
+	paths, err := bitbucket.ListFiles(context.Background(), ListFilesRequest{
+		Repository: bitbucketServerRepo,
+		Branch:     "main",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"inventory/prod/group_vars/all.yml", "inventory/prod/hosts.ini", "README.md"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestBitbucketServerCreateBranch(t *testing.T) {
+	cases := map[string]struct {
+		fixture string
+		name    string
+
+		expectedError error
+	}{
+		"success case with no error": {
+			fixture: "bitbucket/server/create_branch.json",
+			name:    "release/k3s-v1.29.6+k3s1-update",
+		},
+		"error case with existing branch": {
+			fixture:       "bitbucket/server/create_branch_exists.json",
+			name:          "release/k3s-v1.28.5+k3s1-update",
+			expectedError: ErrBranchExists,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, bodies := newReplayServer(t, c.fixture)
+			bitbucket, _ := NewBitbucketServer(server.URL, "", "some token", server.Client())
+
+			err := bitbucket.CreateBranch(context.Background(), CreateBranchRequest{
+				Repository: bitbucketServerRepo,
+				Base:       "main",
+				Branch:     c.name,
+			})
+			if !errors.Is(err, c.expectedError) {
+				t.Fatalf("expected error %v, got %v", c.expectedError, err)
+			}
+
+			expected := map[string]any{
+				"name":       c.name,
+				"startPoint": "refs/heads/main",
+			}
+			if body := bodies["POST /rest/api/1.0/projects/INFRA/repos/k3s-ansible/branches"]; !reflect.DeepEqual(body, expected) {
+				t.Fatalf("expected %v, got %v", expected, body)
+			}
+		})
+	}
+}
+
+func TestBitbucketServerCommit(t *testing.T) {
+	server, bodies := newReplayServer(t, "bitbucket/server/commit.json")
+	bitbucket, _ := NewBitbucketServer(server.URL, "some-user", "some token", server.Client())
+
+	err := bitbucket.Commit(context.Background(), CommitRequest{
+		Repository: bitbucketServerRepo,
+		Branch:     "release/k3s-v1.29.6+k3s1-update",
+		Message:    "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+		Author:     Author{Name: "k3supdater-bot", Email: "k3supdater-bot@k3s.io"},
+		Files: []File{
+			{Path: "inventory/prod/group_vars/all.yml", Content: []byte("k3s_release_version: v1.29.6+k3s1\n")},
+			{Path: "inventory/staging/group_vars/all.yml", Content: []byte("k3s_release_version: v1.29.6+k3s1\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Every file is committed on top of the previous commit
+	expected := map[string]map[string]any{
+		"PUT /rest/api/1.0/projects/INFRA/repos/k3s-ansible/browse/inventory/prod/group_vars/all.yml": {
+			"branch":         "release/k3s-v1.29.6+k3s1-update",
+			"message":        "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+			"content":        "k3s_release_version: v1.29.6+k3s1\n",
+			"sourceCommitId": "5c1e7a9d3b2f4e6a8c0d1e2f3a4b5c6d7e8f9a0b",
+		},
+		"PUT /rest/api/1.0/projects/INFRA/repos/k3s-ansible/browse/inventory/staging/group_vars/all.yml": {
+			"branch":         "release/k3s-v1.29.6+k3s1-update",
+			"message":        "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+			"content":        "k3s_release_version: v1.29.6+k3s1\n",
+			"sourceCommitId": "a7b8c9d0e1f2a3b4c5d6e7f8a9b0c1d2e3f4a5b6",
+		},
+	}
+	if !reflect.DeepEqual(bodies, expected) {
+		t.Fatalf("expected %v, got %v", expected, bodies)
+	}
+}
+
+func TestBitbucketServerCreatePullRequest(t *testing.T) {
+	server, bodies := newReplayServer(t, "bitbucket/server/create_pull_request.json")
+	bitbucket, _ := NewBitbucketServer(server.URL, "", "some token", server.Client())
+
+	err := bitbucket.CreatePullRequest(context.Background(), CreatePullRequestRequest{
+		Repository: bitbucketServerRepo,
+		Base:       "main",
+		Head:       "release/k3s-v1.29.6+k3s1-update",
+		Title:      "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		Body:       "some release notes",
+		Reviewers:  []string{"cguertin14"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	repository := map[string]any{
+		"slug":    "k3s-ansible",
+		"project": map[string]any{"key": "INFRA"},
+	}
+	expected := map[string]any{
+		"title":       "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		"description": "some release notes",
+		"fromRef":     map[string]any{"id": "refs/heads/release/k3s-v1.29.6+k3s1-update", "repository": repository},
+		"toRef":       map[string]any{"id": "refs/heads/main", "repository": repository},
+		"reviewers": []any{
+			map[string]any{"user": map[string]any{"name": "cguertin14"}},
+		},
+	}
+	if body := bodies["POST /rest/api/1.0/projects/INFRA/repos/k3s-ansible/pull-requests"]; !reflect.DeepEqual(body, expected) {
+		t.Fatalf("expected %v, got %v", expected, body)
+	}
+}
+
+func TestBitbucketServerCommitFailure(t *testing.T) {
+	server, bodies := newReplayServer(t, "bitbucket/server/commit_failure.json")
+	bitbucket, _ := NewBitbucketServer(server.URL, "", "some token", server.Client())
+
+	err := bitbucket.Commit(context.Background(), CommitRequest{
+		Repository: bitbucketServerRepo,
+		Branch:     "release/k3s-v1.29.6+k3s1-update",
+		Message:    "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+		Files: []File{
+			{Path: "inventory/prod/group_vars/all.yml", Content: []byte("k3s_release_version: v1.29.6+k3s1\n")},
+			{Path: "inventory/staging/group_vars/all.yml", Content: []byte("k3s_release_version: v1.29.6+k3s1\n")},
+		},
+	})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// The half updated branch is deleted
+	expected := map[string]any{
+		"name":   "refs/heads/release/k3s-v1.29.6+k3s1-update",
+		"dryRun": false,
+	}
+	if body := bodies["DELETE /rest/branch-utils/1.0/projects/INFRA/repos/k3s-ansible/branches"]; !reflect.DeepEqual(body, expected) {
+		t.Fatalf("expected %v, got %v", expected, body)
+	}
+}
@@ -0,0 +1,279 @@
+//go:build test
+// +build test
+
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var bitbucketCloudRepo = Repository{Owner: "homelab", Name: "k3s-ansible"}
+
+// interaction is a request to a platform API,
+// along with the response it got, as recorded
+// in the testdata directory.
+type interaction struct {
+	Request struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+
+		// Query, when set, must be
+		// matched by the request.
+		Query string `json:"query"`
+	} `json:"request"`
+	Response struct {
+		Status  int               `json:"status"`
+		Headers map[string]string `json:"headers"`
+		Body    string            `json:"body"`
+	} `json:"response"`
+}
+
+// matches
+//
+// Returns whether a request is the recorded one.
+func (i interaction) matches(r *http.Request) bool {
+	if requestKey(r) != i.Request.Method+" "+i.Request.Path {
+		return false
+	}
+	if i.Request.Query == "" {
+		return true
+	}
+
+	query, _ := url.ParseQuery(i.Request.Query)
+	return reflect.DeepEqual(r.URL.Query(), query)
+}
+
+// newReplayServer
+//
+// Starts a stand-in replaying the interactions
+// recorded in a fixture, which only accepts the
+// "some token" access token or app password.
+func newReplayServer(t *testing.T, fixture string) (*httptest.Server, map[string]map[string]any) {
+	content, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		t.Fatalf("error when reading fixture: %s", err)
+	}
+	var interactions []interaction
+	if err = json.Unmarshal(content, &interactions); err != nil {
+		t.Fatalf("error when decoding fixture: %s", err)
+	}
+
+	rs := make(routes)
+	for _, i := range interactions {
+		rs.handle(i.Request.Method, i.Request.Path, func(w http.ResponseWriter, r *http.Request) {
+			for _, i := range interactions {
+				if !i.matches(r) {
+					continue
+				}
+				for name, value := range i.Response.Headers {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(i.Response.Status)
+				fmt.Fprint(w, i.Response.Body)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		})
+	}
+
+	authorizations := []string{
+		"Bearer some token",
+		"Basic " + base64.StdEncoding.EncodeToString([]byte("some-user:some token")),
+	}
+	return newStandInServer(t, rs, func(r *http.Request) bool {
+		authorization := r.Header.Get("Authorization")
+		return authorization == authorizations[0] || authorization == authorizations[1]
+	})
+}
+
+func TestBitbucketCloudGetFile(t *testing.T) {
+	cases := map[string]struct {
+		path     string
+		username string
+		token    string
+
+		expected    string
+		expectError bool
+	}{
+		"success case with access token": {
+			path:     "inventory/prod/group_vars/all.yml",
+			token:    "some token",
+			expected: "k3s_release_version: v1.28.5+k3s1\n",
+		},
+		"success case with app password": {
+			path:     "inventory/prod/group_vars/all.yml",
+			username: "some-user",
+			token:    "some token",
+			expected: "k3s_release_version: v1.28.5+k3s1\n",
+		},
+		"error case with missing file": {
+			path:        "inventory/lab/group_vars/all.yml",
+			token:       "some token",
+			expectError: true,
+		},
+		"error case with wrong token": {
+			path:        "inventory/prod/group_vars/all.yml",
+			token:       "some other token",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newReplayServer(t, "bitbucket/cloud/get_file.json")
+			bitbucket := NewBitbucketCloud(server.URL, c.username, c.token, server.Client())
+
+			content, err := bitbucket.GetFile(context.Background(), GetFileRequest{
+				Repository: bitbucketCloudRepo,
+				Path:       c.path,
+				Branch:     "main",
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(content) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, content)
+			}
+		})
+	}
+}
+
+func TestBitbucketCloudListFiles(t *testing.T) {
+	server, _ := newReplayServer(t, "bitbucket/cloud/list_files.json")
+	bitbucket := NewBitbucketCloud(server.URL, "", "some token", server.Client())
+
+	paths, err := bitbucket.ListFiles(context.Background(), ListFilesRequest{
+		Repository: bitbucketCloudRepo,
+		Branch:     "main",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"inventory/prod/group_vars/all.yml", "README.md"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestBitbucketCloudCreateBranch(t *testing.T) {
+	cases := map[string]struct {
+		fixture string
+		name    string
+
+		expectedError error
+	}{
+		"success case with no error": {
+			fixture: "bitbucket/cloud/create_branch.json",
+			name:    "release/k3s-v1.29.6+k3s1-update",
+		},
+		"error case with existing branch": {
+			fixture:       "bitbucket/cloud/create_branch_exists.json",
+			name:          "release/k3s-v1.28.5+k3s1-update",
+			expectedError: ErrBranchExists,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, bodies := newReplayServer(t, c.fixture)
+			bitbucket := NewBitbucketCloud(server.URL, "", "some token", server.Client())
+
+			err := bitbucket.CreateBranch(context.Background(), CreateBranchRequest{
+				Repository: bitbucketCloudRepo,
+				Base:       "main",
+				Branch:     c.name,
+			})
+			if !errors.Is(err, c.expectedError) {
+				t.Fatalf("expected error %v, got %v", c.expectedError, err)
+			}
+
+			expected := map[string]any{
+				"name":   c.name,
+				"target": map[string]any{"hash": "main"},
+			}
+			if body := bodies["POST /2.0/repositories/homelab/k3s-ansible/refs/branches"]; !reflect.DeepEqual(body, expected) {
+				t.Fatalf("expected %v, got %v", expected, body)
+			}
+		})
+	}
+}
+
+func TestBitbucketCloudCommit(t *testing.T) {
+	server, bodies := newReplayServer(t, "bitbucket/cloud/commit.json")
+	bitbucket := NewBitbucketCloud(server.URL, "", "some token", server.Client())
+
+	err := bitbucket.Commit(context.Background(), CommitRequest{
+		Repository: bitbucketCloudRepo,
+		Branch:     "release/k3s-v1.29.6+k3s1-update",
+		Message:    "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+		Author:     Author{Name: "k3supdater-bot", Email: "k3supdater-bot@k3s.io"},
+		Files: []File{
+			{Path: "inventory/prod/group_vars/all.yml", Content: []byte("k3s_release_version: v1.29.6+k3s1\n")},
+			{Path: "inventory/staging/group_vars/all.yml", Content: []byte("k3s_release_version: v1.29.6+k3s1\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]any{
+		"branch":                               "release/k3s-v1.29.6+k3s1-update",
+		"message":                              "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+		"author":                               "k3supdater-bot <k3supdater-bot@k3s.io>",
+		"inventory/prod/group_vars/all.yml":    "k3s_release_version: v1.29.6+k3s1\n",
+		"inventory/staging/group_vars/all.yml": "k3s_release_version: v1.29.6+k3s1\n",
+	}
+	if body := bodies["POST /2.0/repositories/homelab/k3s-ansible/src"]; !reflect.DeepEqual(body, expected) {
+		t.Fatalf("expected %v, got %v", expected, body)
+	}
+}
+
+func TestBitbucketCloudCreatePullRequest(t *testing.T) {
+	server, bodies := newReplayServer(t, "bitbucket/cloud/create_pull_request.json")
+	bitbucket := NewBitbucketCloud(server.URL, "", "some token", server.Client())
+
+	err := bitbucket.CreatePullRequest(context.Background(), CreatePullRequestRequest{
+		Repository: bitbucketCloudRepo,
+		Base:       "main",
+		Head:       "release/k3s-v1.29.6+k3s1-update",
+		Title:      "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		Body:       "some release notes",
+		Reviewers:  []string{"{d301aafa-d676-4ee0-88be-962be7417567}", "712020:0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]any{
+		"title":       "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		"description": "some release notes",
+		"source":      map[string]any{"branch": map[string]any{"name": "release/k3s-v1.29.6+k3s1-update"}},
+		"destination": map[string]any{"branch": map[string]any{"name": "main"}},
+		"reviewers": []any{
+			map[string]any{"uuid": "{d301aafa-d676-4ee0-88be-962be7417567}"},
+			map[string]any{"account_id": "712020:0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"},
+		},
+		"close_source_branch": true,
+	}
+	if body := bodies["POST /2.0/repositories/homelab/k3s-ansible/pullrequests"]; !reflect.DeepEqual(body, expected) {
+		t.Fatalf("expected %v, got %v", expected, body)
+	}
+}
@@ -0,0 +1,197 @@
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// giteaPerPage is the page size of tree listings,
+// which gitea caps to its own maximum if lower.
+const giteaPerPage = 1000
+
+// Gitea is a gitea, or forgejo, instance,
+// through their shared REST API.
+type Gitea struct {
+	rest *restClient
+}
+
+// NewGitea
+//
+// Returns a gitea (or forgejo) platform authenticated
+// with an access token. The base url is the url of the
+// instance, which is required as there is no public one.
+func NewGitea(baseURL, accessToken string, httpClient *http.Client) (*Gitea, error) {
+	if baseURL == "" {
+		return nil, errors.New("the url of the gitea instance is required")
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Gitea{
+		rest: &restClient{
+			baseURL:    strings.TrimSuffix(baseURL, "/") + "/api/v1",
+			httpClient: httpClient,
+			authorize: func(r *http.Request) {
+				if accessToken != "" {
+					r.Header.Set("Authorization", "token "+accessToken)
+				}
+			},
+		},
+	}, nil
+}
+
+// Make sure Gitea struct
+// implements Platform interface
+var _ Platform = &Gitea{}
+
+// repoPath
+//
+// Returns the API path of a repository.
+func (g *Gitea) repoPath(repo Repository) string {
+	return "/repos/" + url.PathEscape(repo.Owner) + "/" + url.PathEscape(repo.Name)
+}
+
+// escapePath
+//
+// Escapes every segment of a file path.
+func escapePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func (g *Gitea) GetFile(ctx context.Context, req GetFileRequest) ([]byte, error) {
+	return g.rest.raw(ctx,
+		g.repoPath(req.Repository)+"/raw/"+escapePath(req.Path),
+		url.Values{"ref": {req.Branch}},
+	)
+}
+
+func (g *Gitea) ListFiles(ctx context.Context, req ListFilesRequest) ([]string, error) {
+	paths := make([]string, 0)
+	for page, listed := 1, 0; ; page++ {
+		var tree struct {
+			Tree []struct {
+				Path string `json:"path"`
+				Type string `json:"type"`
+			} `json:"tree"`
+			TotalCount int `json:"total_count"`
+		}
+		err := g.rest.do(ctx, http.MethodGet, g.repoPath(req.Repository)+"/git/trees/"+url.PathEscape(req.Branch), url.Values{
+			"recursive": {"true"},
+			"per_page":  {strconv.Itoa(giteaPerPage)},
+			"page":      {strconv.Itoa(page)},
+		}, nil, &tree)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range tree.Tree {
+			if entry.Type == "blob" {
+				paths = append(paths, entry.Path)
+			}
+		}
+
+		listed += len(tree.Tree)
+		if len(tree.Tree) == 0 || listed >= tree.TotalCount {
+			return paths, nil
+		}
+	}
+}
+
+func (g *Gitea) CreateBranch(ctx context.Context, req CreateBranchRequest) error {
+	err := g.rest.do(ctx, http.MethodPost, g.repoPath(req.Repository)+"/branches", nil, map[string]string{
+		"new_branch_name": req.Branch,
+		"old_branch_name": req.Base,
+	}, nil)
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusConflict {
+		return errors.Join(ErrBranchExists, err)
+	}
+
+	return err
+}
+
+// Commit
+//
+// Commits every file at once through the files API
+// (gitea 1.20 and later, or forgejo), which needs
+// the current SHA of each file to update.
+func (g *Gitea) Commit(ctx context.Context, req CommitRequest) error {
+	type fileOperation struct {
+		Operation string `json:"operation"`
+		Path      string `json:"path"`
+		Content   string `json:"content"`
+		SHA       string `json:"sha"`
+	}
+
+	files := make([]fileOperation, 0, len(req.Files))
+	for _, f := range req.Files {
+		var current struct {
+			SHA string `json:"sha"`
+		}
+		err := g.rest.do(ctx, http.MethodGet, g.repoPath(req.Repository)+"/contents/"+escapePath(f.Path), url.Values{
+			"ref": {req.Branch},
+		}, nil, &current)
+		if err != nil {
+			return fmt.Errorf("error when fetching %q: %s", f.Path, err)
+		}
+
+		files = append(files, fileOperation{
+			Operation: "update",
+			Path:      f.Path,
+			Content:   base64.StdEncoding.EncodeToString(f.Content),
+			SHA:       current.SHA,
+		})
+	}
+
+	author := map[string]string{
+		"name":  req.Author.Name,
+		"email": req.Author.Email,
+	}
+	return g.rest.do(ctx, http.MethodPost, g.repoPath(req.Repository)+"/contents", nil, map[string]any{
+		"branch":    req.Branch,
+		"message":   req.Message,
+		"author":    author,
+		"committer": author,
+		"files":     files,
+	}, nil)
+}
+
+// CreatePullRequest
+//
+// Opens a pull request, and requests reviews
+// from its reviewers, which are usernames.
+func (g *Gitea) CreatePullRequest(ctx context.Context, req CreatePullRequestRequest) error {
+	var pr struct {
+		Number int `json:"number"`
+	}
+	err := g.rest.do(ctx, http.MethodPost, g.repoPath(req.Repository)+"/pulls", nil, map[string]string{
+		"base":  req.Base,
+		"head":  req.Head,
+		"title": req.Title,
+		"body":  req.Body,
+	}, &pr)
+	if err != nil || len(req.Reviewers) == 0 {
+		return err
+	}
+
+	err = g.rest.do(ctx, http.MethodPost, fmt.Sprintf("%s/pulls/%d/requested_reviewers", g.repoPath(req.Repository), pr.Number), nil, map[string][]string{
+		"reviewers": req.Reviewers,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("error when requesting reviews: %s", err)
+	}
+
+	return nil
+}
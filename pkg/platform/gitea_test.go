@@ -0,0 +1,289 @@
+//go:build test
+// +build test
+
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const giteaRepoPath = "/api/v1/repos/homelab/k3s-ansible"
+
+var giteaRepo = Repository{Owner: "homelab", Name: "k3s-ansible"}
+
+// newGiteaServer
+//
+// Starts a stand-in of the gitea API, holding
+// a few files of the homelab/k3s-ansible repository.
+func newGiteaServer(t *testing.T) (*httptest.Server, map[string]map[string]any) {
+	// Bodies are recorded before requests are
+	// handled, so that handlers can look into them.
+	var bodies map[string]map[string]any
+	rs := make(routes)
+
+	rs.handle(http.MethodGet, giteaRepoPath+"/raw/inventory/prod/group_vars/all.yml", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != "main" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "k3s_release_version: v1.28.5+k3s1\n")
+	})
+	rs.handle(http.MethodGet, giteaRepoPath+"/contents/inventory/prod/group_vars/all.yml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"path":"inventory/prod/group_vars/all.yml","type":"file","sha":"some sha"}`)
+	})
+	rs.handle(http.MethodGet, giteaRepoPath+"/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprint(w, `{"tree":[{"path":"inventory","type":"tree"},{"path":"inventory/prod/group_vars/all.yml","type":"blob"}],"truncated":true,"page":1,"total_count":3}`)
+		default:
+			fmt.Fprint(w, `{"tree":[{"path":"README.md","type":"blob"}],"truncated":false,"page":2,"total_count":3}`)
+		}
+	})
+	rs.handle(http.MethodPost, giteaRepoPath+"/branches", func(w http.ResponseWriter, r *http.Request) {
+		if bodies[requestKey(r)]["new_branch_name"] == "release/k3s-v1.28.5+k3s1-update" {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"message":"The branch already exists."}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"name":"release/k3s-v1.29.6+k3s1-update"}`)
+	})
+	rs.handle(http.MethodPost, giteaRepoPath+"/contents", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"commit":{"sha":"some other sha"}}`)
+	})
+	rs.handle(http.MethodPost, giteaRepoPath+"/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"number":1}`)
+	})
+	rs.handle(http.MethodPost, giteaRepoPath+"/pulls/1/requested_reviewers", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `[{"id":1,"type":"REQUEST_REVIEW"}]`)
+	})
+
+	server, bodies := newStandInServer(t, rs, func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "token some token"
+	})
+	return server, bodies
+}
+
+func TestNewGitea(t *testing.T) {
+	if _, err := NewGitea("", "some token", nil); err == nil {
+		t.Fatal("expected an error without url")
+	}
+}
+
+func TestGiteaGetFile(t *testing.T) {
+	cases := map[string]struct {
+		path  string
+		token string
+
+		expected    string
+		expectError bool
+	}{
+		"success case with no error": {
+			path:     "inventory/prod/group_vars/all.yml",
+			token:    "some token",
+			expected: "k3s_release_version: v1.28.5+k3s1\n",
+		},
+		"error case with missing file": {
+			path:        "inventory/lab/group_vars/all.yml",
+			token:       "some token",
+			expectError: true,
+		},
+		"error case with wrong token": {
+			path:        "inventory/prod/group_vars/all.yml",
+			token:       "some other token",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newGiteaServer(t)
+			gitea, err := NewGitea(server.URL, c.token, server.Client())
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			content, err := gitea.GetFile(context.Background(), GetFileRequest{
+				Repository: giteaRepo,
+				Path:       c.path,
+				Branch:     "main",
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(content) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, content)
+			}
+		})
+	}
+}
+
+func TestGiteaListFiles(t *testing.T) {
+	server, _ := newGiteaServer(t)
+	gitea, _ := NewGitea(server.URL, "some token", server.Client())
+
+	paths, err := gitea.ListFiles(context.Background(), ListFilesRequest{
+		Repository: giteaRepo,
+		Branch:     "main",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"inventory/prod/group_vars/all.yml", "README.md"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestGiteaCreateBranch(t *testing.T) {
+	cases := map[string]struct {
+		name string
+
+		expectedError error
+	}{
+		"success case with no error": {
+			name: "release/k3s-v1.29.6+k3s1-update",
+		},
+		"error case with existing branch": {
+			name:          "release/k3s-v1.28.5+k3s1-update",
+			expectedError: ErrBranchExists,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, bodies := newGiteaServer(t)
+			gitea, _ := NewGitea(server.URL, "some token", server.Client())
+
+			err := gitea.CreateBranch(context.Background(), CreateBranchRequest{
+				Repository: giteaRepo,
+				Base:       "main",
+				Branch:     c.name,
+			})
+			if !errors.Is(err, c.expectedError) {
+				t.Fatalf("expected error %v, got %v", c.expectedError, err)
+			}
+
+			expected := map[string]any{
+				"new_branch_name": c.name,
+				"old_branch_name": "main",
+			}
+			if body := bodies["POST "+giteaRepoPath+"/branches"]; !reflect.DeepEqual(body, expected) {
+				t.Fatalf("expected %v, got %v", expected, body)
+			}
+		})
+	}
+}
+
+func TestGiteaCommit(t *testing.T) {
+	cases := map[string]struct {
+		path string
+
+		expectError bool
+	}{
+		"success case with no error": {
+			path: "inventory/prod/group_vars/all.yml",
+		},
+		"error case with missing file": {
+			path:        "inventory/lab/group_vars/all.yml",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, bodies := newGiteaServer(t)
+			gitea, _ := NewGitea(server.URL, "some token", server.Client())
+
+			err := gitea.Commit(context.Background(), CommitRequest{
+				Repository: giteaRepo,
+				Branch:     "release/k3s-v1.29.6+k3s1-update",
+				Message:    "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+				Author:     Author{Name: "k3supdater-bot", Email: "k3supdater-bot@k3s.io"},
+				Files: []File{
+					{Path: c.path, Content: []byte("k3s_release_version: v1.29.6+k3s1\n")},
+				},
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			author := map[string]any{
+				"name":  "k3supdater-bot",
+				"email": "k3supdater-bot@k3s.io",
+			}
+			expected := map[string]any{
+				"branch":    "release/k3s-v1.29.6+k3s1-update",
+				"message":   "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+				"author":    author,
+				"committer": author,
+				"files": []any{
+					map[string]any{
+						"operation": "update",
+						"path":      "inventory/prod/group_vars/all.yml",
+						"content":   base64.StdEncoding.EncodeToString([]byte("k3s_release_version: v1.29.6+k3s1\n")),
+						"sha":       "some sha",
+					},
+				},
+			}
+			if body := bodies["POST "+giteaRepoPath+"/contents"]; !reflect.DeepEqual(body, expected) {
+				t.Fatalf("expected %v, got %v", expected, body)
+			}
+		})
+	}
+}
+
+func TestGiteaCreatePullRequest(t *testing.T) {
+	server, bodies := newGiteaServer(t)
+	gitea, _ := NewGitea(server.URL, "some token", server.Client())
+
+	err := gitea.CreatePullRequest(context.Background(), CreatePullRequestRequest{
+		Repository: giteaRepo,
+		Base:       "main",
+		Head:       "release/k3s-v1.29.6+k3s1-update",
+		Title:      "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		Body:       "some release notes",
+		Reviewers:  []string{"cguertin14"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]any{
+		"base":  "main",
+		"head":  "release/k3s-v1.29.6+k3s1-update",
+		"title": "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		"body":  "some release notes",
+	}
+	if body := bodies["POST "+giteaRepoPath+"/pulls"]; !reflect.DeepEqual(body, expected) {
+		t.Fatalf("expected %v, got %v", expected, body)
+	}
+
+	expected = map[string]any{"reviewers": []any{"cguertin14"}}
+	if body := bodies["POST "+giteaRepoPath+"/pulls/1/requested_reviewers"]; !reflect.DeepEqual(body, expected) {
+		t.Fatalf("expected %v, got %v", expected, body)
+	}
+}
@@ -0,0 +1,277 @@
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	"github.com/cguertin14/logger"
+	"github.com/google/go-github/v57/github"
+)
+
+// regularFileMode is the git mode of
+// a regular, non executable, file.
+const regularFileMode string = "100644"
+
+// GitHub is the github platform, on top of the
+// github client, which commits through the git
+// data API so that files are committed at once.
+type GitHub struct {
+	client legacy.Client
+}
+
+func NewGitHub(client legacy.Client) *GitHub {
+	return &GitHub{client: client}
+}
+
+// Make sure GitHub struct
+// implements Platform interface
+var _ Platform = &GitHub{}
+
+func (g *GitHub) GetFile(ctx context.Context, req GetFileRequest) ([]byte, error) {
+	repoContent, _, _, err := g.client.GetRepositoryContents(ctx, legacy.GetRepositoryContentsRequest{
+		Owner:  req.Owner,
+		Repo:   req.Name,
+		Path:   req.Path,
+		Branch: req.Branch,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Directories have no content
+	if repoContent == nil || repoContent.Content == nil {
+		return nil, fmt.Errorf("%q is not a file", req.Path)
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(*repoContent.Content)
+	if err != nil {
+		return nil, fmt.Errorf("error when decoding %q: %s", req.Path, err)
+	}
+
+	return decoded, nil
+}
+
+func (g *GitHub) ListFiles(ctx context.Context, req ListFilesRequest) ([]string, error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+
+	tree, _, err := g.client.GetTree(ctx, legacy.GetTreeRequest{
+		Owner:     req.Owner,
+		Repo:      req.Name,
+		SHA:       req.Branch,
+		Recursive: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tree.GetTruncated() {
+		logger.Warnf("The file tree of %s/%s is too large and has been truncated, some files may not be matched.", req.Owner, req.Name)
+	}
+
+	paths := make([]string, 0, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			paths = append(paths, entry.GetPath())
+		}
+	}
+
+	return paths, nil
+}
+
+// CreateBranch
+//
+// Creates a branch from the head of another one.
+func (g *GitHub) CreateBranch(ctx context.Context, req CreateBranchRequest) error {
+	branch, _, err := g.client.GetBranch(ctx, legacy.GetBranchRequest{
+		Owner:      req.Owner,
+		Repo:       req.Name,
+		BranchName: fmt.Sprintf("refs/heads/%s", req.Base),
+	})
+	if err != nil {
+		return fmt.Errorf("error when fetching branch %q: %s", req.Base, err)
+	}
+
+	_, _, err = g.client.CreateBranch(ctx, legacy.CreateBranchRequest{
+		Owner: req.Owner,
+		Repo:  req.Name,
+		Reference: &github.Reference{
+			Ref:    github.String(fmt.Sprintf("refs/heads/%s", req.Branch)),
+			Object: branch.Object,
+		},
+	})
+	if err != nil {
+		// i.e.: "Reference already exists"
+		if strings.Contains(err.Error(), "exists") {
+			return fmt.Errorf("%w: %s", ErrBranchExists, err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// treeModes
+//
+// Returns the mode of every file of a
+// tree (i.e.: 100755 for executables).
+func (g *GitHub) treeModes(ctx context.Context, repo Repository, sha string) (map[string]string, error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+
+	tree, _, err := g.client.GetTree(ctx, legacy.GetTreeRequest{
+		Owner:     repo.Owner,
+		Repo:      repo.Name,
+		SHA:       sha,
+		Recursive: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error when fetching tree %q: %s", sha, err)
+	}
+	if tree.GetTruncated() {
+		logger.Warnf("The file tree of %s/%s is too large and has been truncated, files missing from it are committed as regular files.", repo.Owner, repo.Name)
+	}
+
+	modes := make(map[string]string, len(tree.Entries))
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			modes[entry.GetPath()] = entry.GetMode()
+		}
+	}
+
+	return modes, nil
+}
+
+// Commit
+//
+// Creates a blob per file, and a single tree and
+// commit on top of the head of the branch, which
+// is then moved to the new commit.
+func (g *GitHub) Commit(ctx context.Context, req CommitRequest) error {
+	ref := fmt.Sprintf("refs/heads/%s", req.Branch)
+	branch, _, err := g.client.GetBranch(ctx, legacy.GetBranchRequest{
+		Owner:      req.Owner,
+		Repo:       req.Name,
+		BranchName: ref,
+	})
+	if err != nil {
+		return fmt.Errorf("error when fetching branch %q: %s", req.Branch, err)
+	}
+
+	parent, _, err := g.client.GetCommit(ctx, legacy.GetCommitRequest{
+		Owner: req.Owner,
+		Repo:  req.Name,
+		SHA:   branch.GetObject().GetSHA(),
+	})
+	if err != nil {
+		return fmt.Errorf("error when fetching commit %q: %s", branch.GetObject().GetSHA(), err)
+	}
+
+	// Modes of the committed files are kept,
+	// so that scripts remain executable.
+	modes, err := g.treeModes(ctx, req.Repository, parent.GetTree().GetSHA())
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*github.TreeEntry, 0, len(req.Files))
+	for _, f := range req.Files {
+		blob, _, err := g.client.CreateBlob(ctx, legacy.CreateBlobRequest{
+			Owner: req.Owner,
+			Repo:  req.Name,
+			Blob: &github.Blob{
+				Content:  github.String(base64.StdEncoding.EncodeToString(f.Content)),
+				Encoding: github.String("base64"),
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("error when updating file %q: %s", f.Path, err)
+		}
+
+		mode, ok := modes[f.Path]
+		if !ok {
+			mode = regularFileMode
+		}
+		entries = append(entries, &github.TreeEntry{
+			Path: github.String(f.Path),
+			Mode: github.String(mode),
+			Type: github.String("blob"),
+			SHA:  blob.SHA,
+		})
+	}
+
+	tree, _, err := g.client.CreateTree(ctx, legacy.CreateTreeRequest{
+		Owner:    req.Owner,
+		Repo:     req.Name,
+		BaseTree: parent.GetTree().GetSHA(),
+		Entries:  entries,
+	})
+	if err != nil {
+		return fmt.Errorf("error when creating tree: %s", err)
+	}
+
+	commit, _, err := g.client.CreateCommit(ctx, legacy.CreateCommitRequest{
+		Owner: req.Owner,
+		Repo:  req.Name,
+		Commit: &github.Commit{
+			Message: github.String(req.Message),
+			Tree:    &github.Tree{SHA: tree.SHA},
+			Parents: []*github.Commit{{SHA: parent.SHA}},
+			Committer: &github.CommitAuthor{
+				Name:  github.String(req.Author.Name),
+				Email: github.String(req.Author.Email),
+				Date:  &github.Timestamp{Time: time.Now()},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error when creating commit: %s", err)
+	}
+
+	_, _, err = g.client.UpdateRef(ctx, legacy.UpdateRefRequest{
+		Owner: req.Owner,
+		Repo:  req.Name,
+		Reference: &github.Reference{
+			Ref:    github.String(ref),
+			Object: &github.GitObject{SHA: commit.SHA},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error when updating branch %q: %s", req.Branch, err)
+	}
+
+	return nil
+}
+
+// CreatePullRequest
+//
+// Opens a pull request, and requests reviews
+// from its reviewers, which are usernames.
+func (g *GitHub) CreatePullRequest(ctx context.Context, req CreatePullRequestRequest) error {
+	pr, _, err := g.client.CreatePullRequest(ctx, legacy.CreatePRRequest{
+		Owner: req.Owner,
+		Repo:  req.Name,
+		NewPullRequest: &github.NewPullRequest{
+			Base:  github.String(req.Base),
+			Head:  github.String(req.Head),
+			Body:  github.String(req.Body),
+			Title: github.String(req.Title),
+		},
+	})
+	if err != nil || len(req.Reviewers) == 0 {
+		return err
+	}
+
+	_, _, err = g.client.RequestReviewers(ctx, legacy.RequestReviewersRequest{
+		Owner:            req.Owner,
+		Repo:             req.Name,
+		Number:           pr.GetNumber(),
+		ReviewersRequest: github.ReviewersRequest{Reviewers: req.Reviewers},
+	})
+	if err != nil {
+		return fmt.Errorf("error when requesting reviews: %s", err)
+	}
+
+	return nil
+}
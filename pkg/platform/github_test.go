@@ -0,0 +1,184 @@
+//go:build test
+// +build test
+
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	github_mocks "github.com/cguertin14/k3supdater/pkg/github/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v57/github"
+)
+
+var githubRepo = Repository{Owner: "cguertin14", Name: "k3s-ansible-ha"}
+
+func TestGitHubGetFile(t *testing.T) {
+	cases := map[string]struct {
+		content *string
+
+		expected    string
+		expectError bool
+	}{
+		"success case with no error": {
+			content:  github.String(base64.StdEncoding.EncodeToString([]byte("k3s_release_version: v1.28.5+k3s1\n"))),
+			expected: "k3s_release_version: v1.28.5+k3s1\n",
+		},
+		"error case with directory": {
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+			githubMockClient.EXPECT().GetRepositoryContents(gomock.Any(), legacy.GetRepositoryContentsRequest{
+				Owner:  "cguertin14",
+				Repo:   "k3s-ansible-ha",
+				Path:   "inventory/prod/group_vars/all.yml",
+				Branch: "main",
+			}).
+				Times(1).
+				Return(&github.RepositoryContent{Content: c.content}, nil, nil, nil)
+
+			content, err := NewGitHub(githubMockClient).GetFile(context.Background(), GetFileRequest{
+				Repository: githubRepo,
+				Path:       "inventory/prod/group_vars/all.yml",
+				Branch:     "main",
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(content) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, content)
+			}
+		})
+	}
+}
+
+func TestGitHubListFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	githubMockClient := github_mocks.NewMockClient(ctrl)
+	githubMockClient.EXPECT().GetTree(gomock.Any(), legacy.GetTreeRequest{
+		Owner:     "cguertin14",
+		Repo:      "k3s-ansible-ha",
+		SHA:       "main",
+		Recursive: true,
+	}).
+		Times(1).
+		Return(&github.Tree{
+			Entries: []*github.TreeEntry{
+				{Path: github.String("inventory"), Type: github.String("tree")},
+				{Path: github.String("inventory/prod/group_vars/all.yml"), Type: github.String("blob")},
+			},
+		}, nil, nil)
+
+	paths, err := NewGitHub(githubMockClient).ListFiles(context.Background(), ListFilesRequest{
+		Repository: githubRepo,
+		Branch:     "main",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"inventory/prod/group_vars/all.yml"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestGitHubCreateBranch(t *testing.T) {
+	cases := map[string]struct {
+		createBranchError error
+
+		expectedError error
+	}{
+		"success case with no error": {},
+		"error case with existing branch": {
+			createBranchError: errors.New("Reference already exists"),
+			expectedError:     ErrBranchExists,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			head := &github.GitObject{SHA: github.String("some sha")}
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+			githubMockClient.EXPECT().GetBranch(gomock.Any(), legacy.GetBranchRequest{
+				Owner:      "cguertin14",
+				Repo:       "k3s-ansible-ha",
+				BranchName: "refs/heads/main",
+			}).
+				Times(1).
+				Return(&github.Reference{Object: head}, nil, nil)
+			githubMockClient.EXPECT().CreateBranch(gomock.Any(), legacy.CreateBranchRequest{
+				Owner: "cguertin14",
+				Repo:  "k3s-ansible-ha",
+				Reference: &github.Reference{
+					Ref:    github.String("refs/heads/release/k3s-v1.29.6+k3s1-update"),
+					Object: head,
+				},
+			}).
+				Times(1).
+				Return(nil, nil, c.createBranchError)
+
+			err := NewGitHub(githubMockClient).CreateBranch(context.Background(), CreateBranchRequest{
+				Repository: githubRepo,
+				Base:       "main",
+				Branch:     "release/k3s-v1.29.6+k3s1-update",
+			})
+			if !errors.Is(err, c.expectedError) {
+				t.Fatalf("expected error %v, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestGitHubCreatePullRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	githubMockClient := github_mocks.NewMockClient(ctrl)
+	githubMockClient.EXPECT().CreatePullRequest(gomock.Any(), legacy.CreatePRRequest{
+		Owner: "cguertin14",
+		Repo:  "k3s-ansible-ha",
+		NewPullRequest: &github.NewPullRequest{
+			Base:  github.String("main"),
+			Head:  github.String("release/k3s-v1.29.6+k3s1-update"),
+			Title: github.String("new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1"),
+			Body:  github.String("some release notes"),
+		},
+	}).
+		Times(1).
+		Return(&github.PullRequest{Number: github.Int(12)}, nil, nil)
+
+	err := NewGitHub(githubMockClient).CreatePullRequest(context.Background(), CreatePullRequestRequest{
+		Repository: githubRepo,
+		Base:       "main",
+		Head:       "release/k3s-v1.29.6+k3s1-update",
+		Title:      "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		Body:       "some release notes",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,185 @@
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// DefaultGitLabURL is the url of gitlab.com,
+// used unless a self-hosted instance is set.
+const DefaultGitLabURL string = "https://gitlab.com"
+
+// gitlabPerPage is the maximum page
+// size allowed by the gitlab API.
+const gitlabPerPage = 100
+
+// GitLab is the gitlab platform, either gitlab.com
+// or a self-hosted instance, through its REST API.
+type GitLab struct {
+	rest *restClient
+}
+
+// NewGitLab
+//
+// Returns a gitlab platform authenticated with
+// a personal, group or project access token.
+// The base url is the url of the instance.
+func NewGitLab(baseURL, accessToken string, httpClient *http.Client) *GitLab {
+	if baseURL == "" {
+		baseURL = DefaultGitLabURL
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &GitLab{
+		rest: &restClient{
+			baseURL:    strings.TrimSuffix(baseURL, "/") + "/api/v4",
+			httpClient: httpClient,
+			authorize: func(r *http.Request) {
+				if accessToken != "" {
+					r.Header.Set("PRIVATE-TOKEN", accessToken)
+				}
+			},
+		},
+	}
+}
+
+// Make sure GitLab struct
+// implements Platform interface
+var _ Platform = &GitLab{}
+
+// projectPath
+//
+// Returns the API path of a project, which is
+// identified by its url encoded full path.
+func (g *GitLab) projectPath(repo Repository) string {
+	return "/projects/" + url.PathEscape(repo.Owner+"/"+repo.Name)
+}
+
+func (g *GitLab) GetFile(ctx context.Context, req GetFileRequest) ([]byte, error) {
+	return g.rest.raw(ctx,
+		g.projectPath(req.Repository)+"/repository/files/"+url.PathEscape(req.Path)+"/raw",
+		url.Values{"ref": {req.Branch}},
+	)
+}
+
+func (g *GitLab) ListFiles(ctx context.Context, req ListFilesRequest) ([]string, error) {
+	paths := make([]string, 0)
+	for page := 1; page != 0; {
+		resp, err := g.rest.request(ctx, http.MethodGet, g.projectPath(req.Repository)+"/repository/tree", url.Values{
+			"ref":       {req.Branch},
+			"recursive": {"true"},
+			"per_page":  {strconv.Itoa(gitlabPerPage)},
+			"page":      {strconv.Itoa(page)},
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var entries []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		}
+		err = decodeJSON(resp, &entries)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Type == "blob" {
+				paths = append(paths, entry.Path)
+			}
+		}
+
+		// The next page is empty on the last one
+		page, _ = strconv.Atoi(resp.Header.Get("X-Next-Page"))
+	}
+
+	return paths, nil
+}
+
+func (g *GitLab) CreateBranch(ctx context.Context, req CreateBranchRequest) error {
+	err := g.rest.do(ctx, http.MethodPost, g.projectPath(req.Repository)+"/repository/branches", url.Values{
+		"branch": {req.Branch},
+		"ref":    {req.Base},
+	}, nil, nil)
+
+	// i.e.: {"message":"Branch already exists"}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusBadRequest && strings.Contains(httpErr.Message, "already exists") {
+		return errors.Join(ErrBranchExists, err)
+	}
+
+	return err
+}
+
+// Commit
+//
+// Commits every file at once through the
+// commits API, with one action per file.
+func (g *GitLab) Commit(ctx context.Context, req CommitRequest) error {
+	type action struct {
+		Action   string `json:"action"`
+		FilePath string `json:"file_path"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+
+	actions := make([]action, 0, len(req.Files))
+	for _, f := range req.Files {
+		actions = append(actions, action{
+			Action:   "update",
+			FilePath: f.Path,
+			Content:  base64.StdEncoding.EncodeToString(f.Content),
+			Encoding: "base64",
+		})
+	}
+
+	return g.rest.do(ctx, http.MethodPost, g.projectPath(req.Repository)+"/repository/commits", nil, map[string]any{
+		"branch":         req.Branch,
+		"commit_message": req.Message,
+		"author_name":    req.Author.Name,
+		"author_email":   req.Author.Email,
+		"actions":        actions,
+	}, nil)
+}
+
+// CreatePullRequest
+//
+// Opens a merge request, whose branch is removed
+// once it is merged. Reviewers are usernames,
+// which are looked up for their user id.
+func (g *GitLab) CreatePullRequest(ctx context.Context, req CreatePullRequestRequest) error {
+	body := map[string]any{
+		"source_branch":        req.Head,
+		"target_branch":        req.Base,
+		"title":                req.Title,
+		"description":          req.Body,
+		"remove_source_branch": true,
+	}
+	if len(req.Reviewers) > 0 {
+		ids := make([]int, 0, len(req.Reviewers))
+		for _, reviewer := range req.Reviewers {
+			var users []struct {
+				ID int `json:"id"`
+			}
+			err := g.rest.do(ctx, http.MethodGet, "/users", url.Values{"username": {reviewer}}, nil, &users)
+			if err != nil {
+				return fmt.Errorf("error when looking up reviewer %q: %s", reviewer, err)
+			}
+			if len(users) == 0 {
+				return fmt.Errorf("error when looking up reviewer %q: user not found", reviewer)
+			}
+			ids = append(ids, users[0].ID)
+		}
+		body["reviewer_ids"] = ids
+	}
+
+	return g.rest.do(ctx, http.MethodPost, g.projectPath(req.Repository)+"/merge_requests", nil, body, nil)
+}
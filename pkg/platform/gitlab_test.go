@@ -0,0 +1,268 @@
+//go:build test
+// +build test
+
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+const gitlabProject = "/api/v4/projects/infra%2Fclusters"
+
+var gitlabRepo = Repository{Owner: "infra", Name: "clusters"}
+
+// newGitLabServer
+//
+// Starts a stand-in of the gitlab API, holding
+// a few files of the infra/clusters project.
+func newGitLabServer(t *testing.T) (*httptest.Server, map[string]map[string]any) {
+	rs := make(routes)
+
+	rs.handle(http.MethodGet, gitlabProject+"/repository/files/inventory%2Fprod%2Fgroup_vars%2Fall.yml/raw", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ref") != "main" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "k3s_release_version: v1.28.5+k3s1\n")
+	})
+	rs.handle(http.MethodGet, gitlabProject+"/repository/tree", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "1":
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"path":"inventory","type":"tree"},{"path":"inventory/prod/group_vars/all.yml","type":"blob"}]`)
+		default:
+			w.Header().Set("X-Next-Page", "")
+			fmt.Fprint(w, `[{"path":"README.md","type":"blob"}]`)
+		}
+	})
+	rs.handle(http.MethodPost, gitlabProject+"/repository/branches", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("branch") == "release/k3s-v1.28.5+k3s1-update" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"message":"Branch already exists"}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"name":%q}`, r.URL.Query().Get("branch"))
+	})
+	rs.handle(http.MethodPost, gitlabProject+"/repository/commits", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"some sha"}`)
+	})
+	rs.handle(http.MethodPost, gitlabProject+"/merge_requests", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"iid":1}`)
+	})
+	rs.handle(http.MethodGet, "/api/v4/users", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("username") != "cguertin14" {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprint(w, `[{"id":7,"username":"cguertin14"}]`)
+	})
+
+	return newStandInServer(t, rs, func(r *http.Request) bool {
+		return r.Header.Get("PRIVATE-TOKEN") == "some token"
+	})
+}
+
+func TestGitLabGetFile(t *testing.T) {
+	cases := map[string]struct {
+		path  string
+		token string
+
+		expected    string
+		expectError bool
+	}{
+		"success case with no error": {
+			path:     "inventory/prod/group_vars/all.yml",
+			token:    "some token",
+			expected: "k3s_release_version: v1.28.5+k3s1\n",
+		},
+		"error case with missing file": {
+			path:        "inventory/lab/group_vars/all.yml",
+			token:       "some token",
+			expectError: true,
+		},
+		"error case with wrong token": {
+			path:        "inventory/prod/group_vars/all.yml",
+			token:       "some other token",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newGitLabServer(t)
+			gitlab := NewGitLab(server.URL, c.token, server.Client())
+
+			content, err := gitlab.GetFile(context.Background(), GetFileRequest{
+				Repository: gitlabRepo,
+				Path:       c.path,
+				Branch:     "main",
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(content) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, content)
+			}
+		})
+	}
+}
+
+func TestGitLabListFiles(t *testing.T) {
+	server, _ := newGitLabServer(t)
+	gitlab := NewGitLab(server.URL, "some token", server.Client())
+
+	paths, err := gitlab.ListFiles(context.Background(), ListFilesRequest{
+		Repository: gitlabRepo,
+		Branch:     "main",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{"inventory/prod/group_vars/all.yml", "README.md"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+}
+
+func TestGitLabCreateBranch(t *testing.T) {
+	cases := map[string]struct {
+		name string
+
+		expectedError error
+	}{
+		"success case with no error": {
+			name: "release/k3s-v1.29.6+k3s1-update",
+		},
+		"error case with existing branch": {
+			name:          "release/k3s-v1.28.5+k3s1-update",
+			expectedError: ErrBranchExists,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, _ := newGitLabServer(t)
+			gitlab := NewGitLab(server.URL, "some token", server.Client())
+
+			err := gitlab.CreateBranch(context.Background(), CreateBranchRequest{
+				Repository: gitlabRepo,
+				Base:       "main",
+				Branch:     c.name,
+			})
+			if !errors.Is(err, c.expectedError) {
+				t.Fatalf("expected error %v, got %v", c.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestGitLabCommit(t *testing.T) {
+	server, bodies := newGitLabServer(t)
+	gitlab := NewGitLab(server.URL, "some token", server.Client())
+
+	err := gitlab.Commit(context.Background(), CommitRequest{
+		Repository: gitlabRepo,
+		Branch:     "release/k3s-v1.29.6+k3s1-update",
+		Message:    "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+		Author:     Author{Name: "k3supdater-bot", Email: "k3supdater-bot@k3s.io"},
+		Files: []File{
+			{Path: "inventory/prod/group_vars/all.yml", Content: []byte("k3s_release_version: v1.29.6+k3s1\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]any{
+		"branch":         "release/k3s-v1.29.6+k3s1-update",
+		"commit_message": "Updated k3s version v1.28.5+k3s1 to v1.29.6+k3s1.",
+		"author_name":    "k3supdater-bot",
+		"author_email":   "k3supdater-bot@k3s.io",
+		"actions": []any{
+			map[string]any{
+				"action":    "update",
+				"file_path": "inventory/prod/group_vars/all.yml",
+				"content":   base64.StdEncoding.EncodeToString([]byte("k3s_release_version: v1.29.6+k3s1\n")),
+				"encoding":  "base64",
+			},
+		},
+	}
+	if body := bodies["POST "+gitlabProject+"/repository/commits"]; !reflect.DeepEqual(body, expected) {
+		t.Fatalf("expected %v, got %v", expected, body)
+	}
+}
+
+func TestGitLabCreatePullRequest(t *testing.T) {
+	cases := map[string]struct {
+		reviewers []string
+
+		expectedReviewers any
+		expectError       bool
+	}{
+		"success case with no reviewers": {},
+		"success case with reviewers": {
+			reviewers:         []string{"cguertin14"},
+			expectedReviewers: []any{float64(7)},
+		},
+		"error case with unknown reviewer": {
+			reviewers:   []string{"some-other-user"},
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server, bodies := newGitLabServer(t)
+			gitlab := NewGitLab(server.URL, "some token", server.Client())
+
+			err := gitlab.CreatePullRequest(context.Background(), CreatePullRequestRequest{
+				Repository: gitlabRepo,
+				Base:       "main",
+				Head:       "release/k3s-v1.29.6+k3s1-update",
+				Title:      "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+				Body:       "some release notes",
+				Reviewers:  c.reviewers,
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			expected := map[string]any{
+				"source_branch":        "release/k3s-v1.29.6+k3s1-update",
+				"target_branch":        "main",
+				"title":                "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+				"description":          "some release notes",
+				"remove_source_branch": true,
+			}
+			if c.expectedReviewers != nil {
+				expected["reviewer_ids"] = c.expectedReviewers
+			}
+			if body := bodies["POST "+gitlabProject+"/merge_requests"]; !reflect.DeepEqual(body, expected) {
+				t.Fatalf("expected %v, got %v", expected, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package platform
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrBranchExists is returned when creating
+// a branch which already exists.
+var ErrBranchExists = errors.New("branch already exists")
+
+// Repository is a repository hosted on a platform.
+// Owner is the user, organization or group (i.e.:
+// cguertin14, or infra/clusters on gitlab), or the
+// project key on bitbucket.
+type Repository struct {
+	Owner string
+	Name  string
+}
+
+type GetFileRequest struct {
+	Repository
+	Path   string
+	Branch string
+}
+
+type ListFilesRequest struct {
+	Repository
+	Branch string
+}
+
+type CreateBranchRequest struct {
+	Repository
+
+	// Base is the branch the
+	// new branch starts from.
+	Base   string
+	Branch string
+}
+
+// File is the content of a file to commit.
+type File struct {
+	Path    string
+	Content []byte
+}
+
+// Author is the author of a commit.
+type Author struct {
+	Name  string
+	Email string
+}
+
+type CommitRequest struct {
+	Repository
+	Branch  string
+	Message string
+	Author  Author
+	Files   []File
+}
+
+type CreatePullRequestRequest struct {
+	Repository
+
+	// Base is the branch to merge into,
+	// and Head the branch to merge.
+	Base  string
+	Head  string
+	Title string
+	Body  string
+
+	// Reviewers are the users requested to
+	// review the pull request, identified as
+	// expected by the platform (i.e.: usernames).
+	Reviewers []string
+}
+
+// Platform is a git hosting platform, holding the
+// repositories to update. Pull requests are named
+// after the platform (i.e.: merge requests on gitlab).
+type Platform interface {
+	// GetFile
+	//
+	// Fetches the content of a file on a given branch.
+	GetFile(ctx context.Context, req GetFileRequest) ([]byte, error)
+
+	// ListFiles
+	//
+	// Returns the path of every file of a given branch.
+	ListFiles(ctx context.Context, req ListFilesRequest) ([]string, error)
+
+	// CreateBranch
+	//
+	// Creates a branch from the head of another one,
+	// or returns ErrBranchExists if it already exists.
+	CreateBranch(ctx context.Context, req CreateBranchRequest) error
+
+	// Commit
+	//
+	// Commits the content of several files at once
+	// on top of a given branch, which must never be
+	// left with only some of the files committed.
+	Commit(ctx context.Context, req CommitRequest) error
+
+	// CreatePullRequest
+	//
+	// Opens a pull request between two branches,
+	// and requests reviews from its reviewers.
+	CreatePullRequest(ctx context.Context, req CreatePullRequestRequest) error
+}
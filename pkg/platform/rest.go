@@ -0,0 +1,164 @@
+package platform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// form is a multipart/form-data request body,
+// of which fields are sent in alphabetical order.
+type form map[string]string
+
+// encode
+//
+// Returns the encoded form, along with its content type.
+func (f form) encode() (io.Reader, string, error) {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for _, name := range names {
+		if err := w.WriteField(name, f[name]); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf, w.FormDataContentType(), nil
+}
+
+// HTTPError is an error response of a REST API.
+type HTTPError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *HTTPError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unexpected status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status %d: %s", e.StatusCode, e.Message)
+}
+
+// restClient calls the REST API of a platform.
+type restClient struct {
+	baseURL    string
+	httpClient *http.Client
+
+	// authorize sets the credentials of a request.
+	authorize func(r *http.Request)
+}
+
+// request
+//
+// Sends a request to the API and returns its response,
+// or an HTTPError when it isn't successful. Bodies
+// which aren't readers or forms are sent as JSON.
+func (c *restClient) request(ctx context.Context, method, path string, query url.Values, body any) (*http.Response, error) {
+	target := strings.TrimSuffix(c.baseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var (
+		reader      io.Reader
+		contentType string
+	)
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+	case form:
+		var err error
+		reader, contentType, err = b.encode()
+		if err != nil {
+			return nil, fmt.Errorf("error when encoding request: %s", err)
+		}
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			return nil, fmt.Errorf("error when encoding request: %s", err)
+		}
+		reader, contentType = bytes.NewReader(encoded), "application/json"
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	if c.authorize != nil {
+		c.authorize(r)
+	}
+
+	resp, err := c.httpClient.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		content, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, &HTTPError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(content))}
+	}
+
+	return resp, nil
+}
+
+// do
+//
+// Sends a request to the API and decodes
+// its JSON response into out, if any.
+func (c *restClient) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	resp, err := c.request(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return resp.Body.Close()
+	}
+
+	return decodeJSON(resp, out)
+}
+
+// decodeJSON
+//
+// Decodes and closes the body of a response.
+func decodeJSON(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error when decoding response: %s", err)
+	}
+
+	return nil
+}
+
+// raw
+//
+// Sends a request to the API and
+// returns its body as is.
+func (c *restClient) raw(ctx context.Context, path string, query url.Values) ([]byte, error) {
+	resp, err := c.request(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
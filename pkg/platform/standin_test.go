@@ -0,0 +1,78 @@
+//go:build test
+// +build test
+
+package platform
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// routes are the handlers of a stand-in,
+// by request key (see requestKey).
+type routes map[string]http.HandlerFunc
+
+// handle
+//
+// Routes the requests with a method
+// and an escaped path to a handler.
+func (rs routes) handle(method, path string, handler http.HandlerFunc) {
+	rs[method+" "+path] = handler
+}
+
+// requestKey
+//
+// Returns the key by which stand-ins route requests
+// and record their bodies, i.e.: "POST /api/v1/repos".
+// Paths are escaped, since gitlab project ids are url
+// encoded (i.e.: "/api/v4/projects/infra%2Fclusters").
+func requestKey(r *http.Request) string {
+	return r.Method + " " + r.URL.EscapedPath()
+}
+
+// newStandInServer
+//
+// Starts a stand-in of a platform API serving routes,
+// which rejects the requests that are not authorized,
+// and records the bodies of the others (JSON or form
+// fields) by request key before they are handled.
+func newStandInServer(t *testing.T, rs routes, authorized func(r *http.Request) bool) (*httptest.Server, map[string]map[string]any) {
+	bodies := make(map[string]map[string]any)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := requestKey(r)
+		handler, ok := rs[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !authorized(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch contentType := r.Header.Get("Content-Type"); {
+		case contentType == "application/json":
+			body := make(map[string]any)
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("unexpected body: %s", err)
+			}
+			bodies[key] = body
+		case strings.HasPrefix(contentType, "multipart/form-data"):
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Errorf("unexpected body: %s", err)
+			}
+			body := make(map[string]any)
+			for name, values := range r.MultipartForm.Value {
+				body[name] = values[0]
+			}
+			bodies[key] = body
+		}
+
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, bodies
+}
@@ -0,0 +1,76 @@
+package updater
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/google/go-github/v57/github"
+)
+
+// k3sBinaries maps the architectures to the name
+// of the k3s binary downloaded by the playbooks.
+var k3sBinaries = map[string]string{
+	"amd64": "k3s",
+	"arm64": "k3s-arm64",
+	"arm":   "k3s-armhf",
+	"s390x": "k3s-s390x",
+}
+
+// checksumsAsset
+//
+// Returns the name of the checksums
+// file of a given architecture.
+func checksumsAsset(arch string) string {
+	return fmt.Sprintf("sha256sum-%s.txt", arch)
+}
+
+// requiredAssets
+//
+// Returns the asset name patterns a release must have
+// to be proposed. Defaults to the k3s binary and the
+// checksums file of every declared architecture.
+func requiredAssets(req UpdateReleaseReq) ([]string, error) {
+	if len(req.RequiredAssets) > 0 {
+		for _, pattern := range req.RequiredAssets {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("error when parsing required asset %q: %s", pattern, err)
+			}
+		}
+		return req.RequiredAssets, nil
+	}
+
+	assets := make([]string, 0, 2*len(req.Architectures))
+	for _, arch := range req.Architectures {
+		binary, ok := k3sBinaries[arch]
+		if !ok {
+			return nil, fmt.Errorf("error when listing required assets: unknown architecture %q", arch)
+		}
+		assets = append(assets, binary, checksumsAsset(arch))
+	}
+
+	return assets, nil
+}
+
+// missingAssets
+//
+// Returns the patterns which don't match
+// any of the assets of a given release.
+func missingAssets(r *github.RepositoryRelease, patterns []string) []string {
+	missing := make([]string, 0)
+	for _, pattern := range patterns {
+		found := false
+		for _, asset := range r.Assets {
+			// patterns have been validated
+			// beforehand, so errors are ignored.
+			if matched, _ := path.Match(pattern, asset.GetName()); matched {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, pattern)
+		}
+	}
+
+	return missing
+}
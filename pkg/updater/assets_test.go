@@ -0,0 +1,119 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func newTestReleaseWithAssets(name string, assets ...string) *github.RepositoryRelease {
+	r := newTestRelease(name)
+	for _, asset := range assets {
+		r.Assets = append(r.Assets, &github.ReleaseAsset{Name: github.String(asset)})
+	}
+	return r
+}
+
+func TestRequiredAssets(t *testing.T) {
+	cases := map[string]struct {
+		req UpdateReleaseReq
+
+		expected    []string
+		expectError bool
+	}{
+		"success case with architectures": {
+			req:      UpdateReleaseReq{Architectures: []string{"amd64", "arm"}},
+			expected: []string{"k3s", "sha256sum-amd64.txt", "k3s-armhf", "sha256sum-arm.txt"},
+		},
+		"success case with explicit assets": {
+			req: UpdateReleaseReq{
+				Architectures:  []string{"amd64"},
+				RequiredAssets: []string{"k3s-arm64", "k3s-airgap-images-arm64.*"},
+			},
+			expected: []string{"k3s-arm64", "k3s-airgap-images-arm64.*"},
+		},
+		"success case with nothing required": {
+			expected: []string{},
+		},
+		"error case with unknown architecture": {
+			req:         UpdateReleaseReq{Architectures: []string{"riscv64"}},
+			expectError: true,
+		},
+		"error case with invalid pattern": {
+			req:         UpdateReleaseReq{RequiredAssets: []string{"k3s-["}},
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			assets, err := requiredAssets(c.req)
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if strings.Join(assets, ",") != strings.Join(c.expected, ",") {
+				t.Fatalf("expected %v, got %v", c.expected, assets)
+			}
+		})
+	}
+}
+
+func TestEligibleReleasesAssets(t *testing.T) {
+	releases := []*github.RepositoryRelease{
+		// arm64 binaries not uploaded yet
+		newTestReleaseWithAssets("v1.30.3+k3s1", "k3s", "sha256sum-amd64.txt"),
+		newTestReleaseWithAssets("v1.30.2+k3s1", "k3s", "sha256sum-amd64.txt", "k3s-arm64", "sha256sum-arm64.txt"),
+		newTestReleaseWithAssets("v1.30.1+k3s1"),
+	}
+
+	cases := map[string]struct {
+		req UpdateReleaseReq
+
+		expected []string
+	}{
+		"success case with amd64 only": {
+			req:      UpdateReleaseReq{Architectures: []string{"amd64"}},
+			expected: []string{"v1.30.3+k3s1", "v1.30.2+k3s1"},
+		},
+		"success case with arm64 nodes": {
+			req:      UpdateReleaseReq{Architectures: []string{"amd64", "arm64"}},
+			expected: []string{"v1.30.2+k3s1"},
+		},
+		"success case with patterns": {
+			req:      UpdateReleaseReq{RequiredAssets: []string{"sha256sum-*.txt"}},
+			expected: []string{"v1.30.3+k3s1", "v1.30.2+k3s1"},
+		},
+		"success case with tags source": {
+			req: UpdateReleaseReq{
+				Architectures: []string{"amd64", "arm64"},
+				ReleaseSource: ReleaseSourceTags,
+			},
+			expected: []string{"v1.30.3+k3s1", "v1.30.2+k3s1", "v1.30.1+k3s1"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			eligible, _, err := eligibleReleases(context.Background(), c.req, "v1.30.0+k3s1", releases)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := releaseNames(eligible)
+			if strings.Join(got, ",") != strings.Join(c.expected, ",") {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
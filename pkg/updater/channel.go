@@ -0,0 +1,149 @@
+package updater
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	"github.com/cguertin14/logger"
+	"github.com/google/go-github/v57/github"
+)
+
+// DefaultChannelURL is the k3s channel server
+// used by the k3s install script and the
+// system-upgrade-controller.
+const DefaultChannelURL string = "https://update.k3s.io/v1-release/channels"
+
+type channelDocument struct {
+	Data []releaseChannel `json:"data"`
+}
+
+type releaseChannel struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Latest string `json:"latest"`
+}
+
+// readChannelDocument
+//
+// Reads a channel document from the channel server,
+// or from a local file when the location has no
+// http(s) scheme.
+func (c *ClientSet) readChannelDocument(ctx context.Context, location string) (doc *channelDocument, err error) {
+	var content []byte
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("error when parsing channel url %q: %s", location, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error when building channel request: %s", err)
+		}
+		httpReq.Header.Set("Accept", "application/json")
+
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			return nil, fmt.Errorf("error when fetching channels from %q: %s", location, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("error when fetching channels from %q: unexpected status %q", location, resp.Status)
+		}
+
+		if content, err = io.ReadAll(resp.Body); err != nil {
+			return nil, fmt.Errorf("error when reading channels from %q: %s", location, err)
+		}
+	case "file", "":
+		path := u.Path
+		if u.Scheme == "" {
+			path = location
+		}
+		if content, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("error when reading channels from %q: %s", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("error when fetching channels: unsupported scheme %q", u.Scheme)
+	}
+
+	doc = &channelDocument{}
+	if err = json.Unmarshal(content, doc); err != nil {
+		return nil, fmt.Errorf("error when decoding channels from %q: %s", location, err)
+	}
+
+	return doc, nil
+}
+
+// getChannelRelease
+//
+// Resolves the latest version of the requested
+// channel and returns its matching github release.
+func (c *ClientSet) getChannelRelease(ctx context.Context, req UpdateReleaseReq) (*github.RepositoryRelease, error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+
+	location := req.ChannelURL
+	if location == "" {
+		location = DefaultChannelURL
+	}
+	logger.Infof("Fetching the %q channel from %s...", req.Channel, location)
+
+	doc, err := c.readChannelDocument(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	var version string
+	for _, ch := range doc.Data {
+		if ch.ID == req.Channel || ch.Name == req.Channel {
+			version = ch.Latest
+			break
+		}
+	}
+	if version == "" {
+		return nil, fmt.Errorf("error when resolving channel: %q not found in %q", req.Channel, location)
+	}
+
+	release, resp, err := c.client.GetReleaseByTag(ctx, legacy.GetReleaseByTagRequest{
+		Owner: req.ReleaseRepo.Owner,
+		Repo:  req.ReleaseRepo.Name,
+		Tag:   version,
+	})
+	if err != nil {
+		// Any other failure (i.e.: rate limits) must not
+		// be mistaken for a release missing from github.
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return nil, fmt.Errorf("error when fetching release %q from %s/%s: %s", version, req.ReleaseRepo.Owner, req.ReleaseRepo.Name, err)
+		}
+
+		// The channel is the source of truth here, so
+		// a missing github release must not block the
+		// update. Release notes will be missing though,
+		// and its assets and age can't be verified.
+		logger.Warnf("Could not fetch release %q from %s/%s: %s", version, req.ReleaseRepo.Owner, req.ReleaseRepo.Name, err)
+		release = &github.RepositoryRelease{
+			TagName:    github.String(version),
+			Prerelease: github.Bool(false),
+			Body: github.String(fmt.Sprintf(
+				"https://github.com/%s/%s/releases/tag/%s",
+				req.ReleaseRepo.Owner,
+				req.ReleaseRepo.Name,
+				url.PathEscape(version),
+			)),
+		}
+	}
+
+	// Channels reference versions, which is what
+	// the rest of the updater expects as a name.
+	release.Name = github.String(version)
+
+	return release, nil
+}
@@ -0,0 +1,162 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	github_mocks "github.com/cguertin14/k3supdater/pkg/github/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v57/github"
+)
+
+const channelsFixture = `{
+	"type": "collection",
+	"data": [
+		{"id": "stable", "type": "channel", "name": "stable", "latest": "v1.29.6+k3s2"},
+		{"id": "latest", "type": "channel", "name": "latest", "latest": "v1.30.2+k3s1"},
+		{"id": "v1.28", "type": "channel", "name": "v1.28", "latest": "v1.28.11+k3s1"}
+	]
+}`
+
+func TestGetChannelRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1-release/channels" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(channelsFixture))
+	}))
+	defer server.Close()
+
+	localFile := filepath.Join(t.TempDir(), "channels.json")
+	if err := os.WriteFile(localFile, []byte(channelsFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		channel        string
+		channelURL     string
+		getReleaseErr  error
+		getReleaseResp *github.Response
+		expectedBody   string
+		expectedLookup string
+
+		expectedVersion string
+		expectError     bool
+	}{
+		"success case with stable channel": {
+			channel:         "stable",
+			channelURL:      server.URL + "/v1-release/channels",
+			expectedLookup:  "v1.29.6+k3s2",
+			expectedBody:    "some release notes",
+			expectedVersion: "v1.29.6+k3s2",
+		},
+		"success case with minor channel from a local file": {
+			channel:         "v1.28",
+			channelURL:      localFile,
+			expectedLookup:  "v1.28.11+k3s1",
+			expectedBody:    "some release notes",
+			expectedVersion: "v1.28.11+k3s1",
+		},
+		"success case with missing github release": {
+			channel:         "latest",
+			channelURL:      "file://" + localFile,
+			expectedLookup:  "v1.30.2+k3s1",
+			getReleaseErr:   errors.New("some error"),
+			getReleaseResp:  &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}},
+			expectedBody:    "https://github.com/k3s-io/k3s/releases/tag/v1.30.2+k3s1",
+			expectedVersion: "v1.30.2+k3s1",
+		},
+		"error case with github error": {
+			channel:        "latest",
+			channelURL:     localFile,
+			expectedLookup: "v1.30.2+k3s1",
+			getReleaseErr:  errors.New("some error"),
+			getReleaseResp: &github.Response{Response: &http.Response{StatusCode: http.StatusForbidden}},
+			expectError:    true,
+		},
+		"error case with network error": {
+			channel:        "latest",
+			channelURL:     localFile,
+			expectedLookup: "v1.30.2+k3s1",
+			getReleaseErr:  errors.New("some error"),
+			expectError:    true,
+		},
+		"error case with unknown channel": {
+			channel:     "testing",
+			channelURL:  localFile,
+			expectError: true,
+		},
+		"error case with channel server error": {
+			channel:     "stable",
+			channelURL:  server.URL + "/some/other/path",
+			expectError: true,
+		},
+		"error case with missing local file": {
+			channel:     "stable",
+			channelURL:  filepath.Join(t.TempDir(), "missing.json"),
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// create new mock client instance
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+
+			// define mock behavior
+			githubMockClient.EXPECT().GetReleaseByTag(gomock.Any(), legacy.GetReleaseByTagRequest{
+				Owner: "k3s-io",
+				Repo:  "k3s",
+				Tag:   c.expectedLookup,
+			}).
+				MaxTimes(1).
+				Return(&github.RepositoryRelease{
+					Name: github.String("some release name"),
+					Body: github.String("some release notes"),
+				}, c.getReleaseResp, c.getReleaseErr)
+
+			// create mock updater client
+			client := NewClient(context.Background(), Dependencies{
+				Client:     githubMockClient,
+				HTTPClient: server.Client(),
+			})
+
+			release, err := client.getChannelRelease(context.Background(), UpdateReleaseReq{
+				ReleaseRepo: Repository{
+					Owner: "k3s-io",
+					Name:  "k3s",
+				},
+				Channel:    c.channel,
+				ChannelURL: c.channelURL,
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if release.GetName() != c.expectedVersion {
+				t.Fatalf("expected version %q, got %q", c.expectedVersion, release.GetName())
+			}
+			if release.GetBody() != c.expectedBody {
+				t.Fatalf("expected body %q, got %q", c.expectedBody, release.GetBody())
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package updater
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cguertin14/logger"
+	"github.com/google/go-github/v57/github"
+)
+
+// checksumPrefix is the algorithm prefix expected by
+// ansible's get_url checksum parameter, which is kept
+// when the playbook already uses it.
+const checksumPrefix string = "sha256:"
+
+type assetChecksum struct {
+	arch   string
+	asset  string
+	sha256 string
+}
+
+// verifyChecksums
+//
+// Downloads the checksums file of every declared
+// architecture and the k3s binary it references,
+// and makes sure they match. The verified checksums
+// are returned in the same order as the architectures.
+func (c *ClientSet) verifyChecksums(ctx context.Context, req UpdateReleaseReq, release *github.RepositoryRelease) ([]assetChecksum, error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+
+	if len(req.Architectures) == 0 {
+		return nil, fmt.Errorf("error when verifying checksums of %q: no architecture declared", *release.Name)
+	}
+
+	checksums := make([]assetChecksum, 0, len(req.Architectures))
+	for _, arch := range req.Architectures {
+		binary, ok := k3sBinaries[arch]
+		if !ok {
+			return nil, fmt.Errorf("error when verifying checksums of %q: unknown architecture %q", *release.Name, arch)
+		}
+		logger.Infof("Verifying the checksum of %q for release %q...", binary, *release.Name)
+
+		manifest := &strings.Builder{}
+		if err := c.downloadAsset(ctx, release, checksumsAsset(arch), manifest); err != nil {
+			return nil, err
+		}
+		expected, err := parseChecksums(manifest.String(), binary)
+		if err != nil {
+			return nil, fmt.Errorf("error when reading %q of %q: %s", checksumsAsset(arch), *release.Name, err)
+		}
+
+		hash := sha256.New()
+		if err = c.downloadAsset(ctx, release, binary, hash); err != nil {
+			return nil, err
+		}
+		if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+			return nil, fmt.Errorf(
+				"error when verifying checksum of %q for release %q: expected %s, got %s",
+				binary,
+				*release.Name,
+				expected,
+				actual,
+			)
+		}
+
+		checksums = append(checksums, assetChecksum{
+			arch:   arch,
+			asset:  binary,
+			sha256: expected,
+		})
+	}
+
+	return checksums, nil
+}
+
+// downloadAsset
+//
+// Downloads a release asset into w, which allows
+// hashing large binaries without keeping them
+// in memory.
+func (c *ClientSet) downloadAsset(ctx context.Context, release *github.RepositoryRelease, name string, w io.Writer) error {
+	var asset *github.ReleaseAsset
+	for _, a := range release.Assets {
+		if a.GetName() == name {
+			asset = a
+			break
+		}
+	}
+	if asset == nil || asset.GetBrowserDownloadURL() == "" {
+		return fmt.Errorf("error when downloading %q: asset not found in release %q", name, *release.Name)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.GetBrowserDownloadURL(), nil)
+	if err != nil {
+		return fmt.Errorf("error when building download request for %q: %s", name, err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("error when downloading %q: %s", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error when downloading %q: unexpected status %q", name, resp.Status)
+	}
+
+	if _, err = io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("error when downloading %q: %s", name, err)
+	}
+
+	return nil
+}
+
+// parseChecksums
+//
+// Returns the checksum of a given file from
+// a sha256sum formatted manifest.
+func parseChecksums(manifest, file string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(manifest))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		// sha256sum prefixes files read
+		// in binary mode with a "*".
+		if strings.TrimPrefix(fields[1], "*") == file {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	return "", fmt.Errorf("no checksum found for %q", file)
+}
+
+// checksumsNote
+//
+// Describes the verified checksums
+// of the proposed release.
+func checksumsNote(checksums []assetChecksum) string {
+	lines := []string{
+		"**Verified checksums**:",
+		"",
+		"| Architecture | Asset | SHA256 |",
+		"| --- | --- | --- |",
+	}
+	for _, checksum := range checksums {
+		lines = append(lines, fmt.Sprintf("| %s | `%s` | `%s` |", checksum.arch, checksum.asset, checksum.sha256))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// setChecksum
+//
+// Replaces the value of the given key with a checksum,
+// keeping the "sha256:" prefix if the file uses it.
+func setChecksum(fileContent []byte, key, checksum string) ([]byte, error) {
+	keyPath, err := parseKeyPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	scalar, err := findYAMLScalar(fileContent, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("error when updating checksum: %w", err)
+	}
+
+	value := checksum
+	if strings.HasPrefix(scalar.node.Value, checksumPrefix) {
+		value = checksumPrefix + checksum
+	}
+
+	return setYAMLScalar(fileContent, keyPath, value)
+}
+
+// updateChecksum
+//
+// Sets the checksum of the first architecture in
+// an updated file, when requested. Only files defining
+// the checksum get it, since overrides (i.e.: host_vars)
+// may only set the version.
+func updateChecksum(req updateFileReq, content []byte) ([]byte, error) {
+	if req.ChecksumKey == "" || len(req.checksums) == 0 {
+		return content, nil
+	}
+
+	updated, err := setChecksum(content, req.ChecksumKey, req.checksums[0].sha256)
+	switch {
+	case errors.Is(err, errKeyNotFound):
+		return content, nil
+	case err != nil:
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// definesKey
+//
+// Returns whether a yaml file defines a given key.
+func definesKey(fileContent, key string) bool {
+	keyPath, err := parseKeyPath(key)
+	if err != nil {
+		return false
+	}
+
+	_, err = findYAMLScalar([]byte(fileContent), keyPath)
+	return err == nil
+}
@@ -0,0 +1,187 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestVerifyChecksums(t *testing.T) {
+	binaries := map[string]string{
+		"k3s":       "some amd64 binary",
+		"k3s-arm64": "some arm64 binary",
+	}
+	sum := func(content string) string {
+		hash := sha256.Sum256([]byte(content))
+		return hex.EncodeToString(hash[:])
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/download/")
+		switch name {
+		case "sha256sum-amd64.txt":
+			fmt.Fprintf(w, "%s  k3s\n%s  k3s-airgap-images-amd64.tar\n", sum(binaries["k3s"]), sum("some images"))
+		case "sha256sum-arm64.txt":
+			fmt.Fprintf(w, "%s *k3s-arm64\n", sum("some tampered binary"))
+		case "sha256sum-arm.txt":
+			fmt.Fprintf(w, "%s  k3s-airgap-images-arm.tar\n", sum("some images"))
+		default:
+			content, ok := binaries[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(content))
+		}
+	}))
+	defer server.Close()
+
+	release := &github.RepositoryRelease{Name: github.String("v1.30.2+k3s1")}
+	for _, name := range []string{"k3s", "k3s-arm64", "k3s-armhf", "sha256sum-amd64.txt", "sha256sum-arm64.txt", "sha256sum-arm.txt"} {
+		release.Assets = append(release.Assets, &github.ReleaseAsset{
+			Name:               github.String(name),
+			BrowserDownloadURL: github.String(server.URL + "/download/" + name),
+		})
+	}
+
+	cases := map[string]struct {
+		architectures []string
+
+		expected    []assetChecksum
+		expectError bool
+	}{
+		"success case with amd64": {
+			architectures: []string{"amd64"},
+			expected: []assetChecksum{
+				{arch: "amd64", asset: "k3s", sha256: sum(binaries["k3s"])},
+			},
+		},
+		"error case with checksum mismatch": {
+			architectures: []string{"amd64", "arm64"},
+			expectError:   true,
+		},
+		"error case with binary missing from checksums file": {
+			architectures: []string{"arm"},
+			expectError:   true,
+		},
+		"error case with missing asset": {
+			architectures: []string{"s390x"},
+			expectError:   true,
+		},
+		"error case with no architecture": {
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			// create updater client
+			client := NewClient(context.Background(), Dependencies{
+				Client:     nil,
+				HTTPClient: server.Client(),
+			})
+
+			checksums, err := client.verifyChecksums(context.Background(), UpdateReleaseReq{
+				Architectures: c.architectures,
+			}, release)
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if fmt.Sprint(checksums) != fmt.Sprint(c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, checksums)
+			}
+		})
+	}
+}
+
+func TestSetChecksum(t *testing.T) {
+	cases := map[string]struct {
+		fileContent string
+
+		expected    string
+		expectError bool
+	}{
+		"success case with plain checksum": {
+			fileContent: "k3s_release_version: v1.30.1+k3s1\nk3s_checksum: abc # amd64\n",
+			expected:    "k3s_release_version: v1.30.1+k3s1\nk3s_checksum: def # amd64\n",
+		},
+		"success case with prefixed checksum": {
+			fileContent: "k3s_checksum: sha256:abc\n",
+			expected:    "k3s_checksum: sha256:def\n",
+		},
+		"success case with empty checksum": {
+			fileContent: "k3s_checksum:\nk3s_release_version: v1.30.1+k3s1\n",
+			expected:    "k3s_checksum: def\nk3s_release_version: v1.30.1+k3s1\n",
+		},
+		"success case with empty checksum and comment": {
+			fileContent: "k3s_checksum: # amd64\n",
+			expected:    "k3s_checksum: def # amd64\n",
+		},
+		"error case with missing key": {
+			fileContent: "k3s_release_version: v1.30.1+k3s1\n",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			content, err := setChecksum([]byte(c.fileContent), "k3s_checksum", "def")
+			if c.expectError {
+				if !errors.Is(err, errKeyNotFound) {
+					t.Fatalf("expected %v, got %v", errKeyNotFound, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(content) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, content)
+			}
+		})
+	}
+}
+
+func TestDefinesKey(t *testing.T) {
+	cases := map[string]struct {
+		fileContent string
+
+		expected bool
+	}{
+		"group_vars defining the key": {
+			fileContent: "k3s_release_version: v1.30.1+k3s1\nk3s_checksum: abc\n",
+			expected:    true,
+		},
+		"host_vars only overriding the version": {
+			fileContent: "k3s_release_version: v1.30.1+k3s1\n",
+		},
+		"not a yaml file": {
+			fileContent: "INSTALL_K3S_VERSION=v1.30.1+k3s1\n",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := definesKey(c.fileContent, "k3s_checksum"); got != c.expected {
+				t.Fatalf("expected %t, got %t", c.expected, got)
+			}
+		})
+	}
+}
@@ -2,27 +2,48 @@ package updater
 
 import (
 	"context"
+	"net/http"
 
 	github "github.com/cguertin14/k3supdater/pkg/github"
+	"github.com/cguertin14/k3supdater/pkg/platform"
 )
 
 type ClientSet struct {
-	client github.Client
+	client     github.Client
+	platform   platform.Platform
+	httpClient *http.Client
 }
 
 type Dependencies struct {
-	Client      github.Client
+	Client github.Client
+
+	// Platform hosts the repository to update.
+	// Releases are always read from github.
+	// Defaults to github, through Client.
+	Platform platform.Platform
+
+	HTTPClient  *http.Client
 	AccessToken string
 }
 
 func NewClient(ctx context.Context, deps Dependencies) *ClientSet {
 	c := &ClientSet{
-		client: deps.Client,
+		client:     deps.Client,
+		platform:   deps.Platform,
+		httpClient: deps.HTTPClient,
 	}
 
 	if deps.Client == nil {
 		c.client = github.NewClient(ctx, deps.AccessToken)
 	}
 
+	if deps.Platform == nil {
+		c.platform = platform.NewGitHub(c.client)
+	}
+
+	if deps.HTTPClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+
 	return c
 }
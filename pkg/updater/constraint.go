@@ -0,0 +1,127 @@
+package updater
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+type versionComparator struct {
+	operator string
+	version  string
+}
+
+// versionConstraint is a set of comparator groups: a
+// version satisfies the constraint when it satisfies
+// every comparator of at least one group.
+type versionConstraint [][]versionComparator
+
+// constraintOperators is ordered so that two characters
+// operators are matched before their one character prefix.
+var constraintOperators = []string{">=", "<=", "!=", "==", ">", "<", "="}
+
+// parseVersionConstraint
+//
+// Parses a semver range such as ">=v1.29.0 <v1.31.0".
+// Comparators are separated by spaces or commas and
+// alternatives by "||". A bare version means "=".
+func parseVersionConstraint(raw string) (versionConstraint, error) {
+	constraint := versionConstraint{}
+	for _, group := range strings.Split(raw, "||") {
+		comparators := make([]versionComparator, 0)
+
+		// allow a space between an operator and its version
+		fields := strings.FieldsFunc(group, func(r rune) bool {
+			return r == ' ' || r == ','
+		})
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			operator := "="
+			for _, op := range constraintOperators {
+				if strings.HasPrefix(field, op) {
+					operator = op
+					field = strings.TrimPrefix(field, op)
+					break
+				}
+			}
+			if operator == "==" {
+				operator = "="
+			}
+			if field == "" && i+1 < len(fields) {
+				i++
+				field = fields[i]
+			}
+
+			version := canonicalVersion(field)
+			if !semver.IsValid(version) {
+				return nil, fmt.Errorf("error when parsing version constraint %q: invalid version %q", raw, field)
+			}
+			comparators = append(comparators, versionComparator{
+				operator: operator,
+				version:  version,
+			})
+		}
+
+		if len(comparators) == 0 {
+			return nil, fmt.Errorf("error when parsing version constraint %q: empty range", raw)
+		}
+		constraint = append(constraint, comparators)
+	}
+
+	return constraint, nil
+}
+
+// check
+//
+// Returns whether the given version
+// satisfies the constraint.
+func (vc versionConstraint) check(version string) bool {
+	for _, group := range vc {
+		satisfied := true
+		for _, comparator := range group {
+			if !comparator.check(version) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true
+		}
+	}
+	return false
+}
+
+func (vc versionComparator) check(version string) bool {
+	// k3s revisions are only compared when the constraint
+	// has one, so that "<=v1.29.4" includes v1.29.4+k3s2.
+	compared := semver.Compare(version, vc.version)
+	if semver.Build(vc.version) != "" {
+		compared = compareK3sVersions(version, vc.version)
+	}
+	switch vc.operator {
+	case ">=":
+		return compared >= 0
+	case "<=":
+		return compared <= 0
+	case ">":
+		return compared > 0
+	case "<":
+		return compared < 0
+	case "!=":
+		return compared != 0
+	default:
+		return compared == 0
+	}
+}
+
+// canonicalVersion
+//
+// Adds the "v" prefix expected by the
+// semver package when it is missing.
+func canonicalVersion(version string) string {
+	if version != "" && !strings.HasPrefix(version, "v") {
+		return "v" + version
+	}
+	return version
+}
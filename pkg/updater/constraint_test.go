@@ -0,0 +1,90 @@
+//go:build test
+// +build test
+
+package updater
+
+import "testing"
+
+func TestVersionConstraint(t *testing.T) {
+	cases := map[string]struct {
+		constraint string
+		versions   map[string]bool
+
+		expectError bool
+	}{
+		"range with spaces": {
+			constraint: ">=v1.29.0 <v1.31.0",
+			versions: map[string]bool{
+				"v1.28.9+k3s1":  false,
+				"v1.29.0+k3s1":  true,
+				"v1.30.14+k3s1": true,
+				"v1.31.0+k3s1":  false,
+			},
+		},
+		"range with commas and spaces after operators": {
+			constraint: "> 1.29.3, <= 1.30",
+			versions: map[string]bool{
+				"v1.29.3+k3s1": false,
+				"v1.29.4+k3s1": true,
+				"v1.30.0+k3s1": true,
+				"v1.30.1+k3s1": false,
+			},
+		},
+		"alternatives": {
+			constraint: "=v1.28.11 || >=v1.30.0",
+			versions: map[string]bool{
+				"v1.28.10+k3s1": false,
+				"v1.28.11+k3s1": true,
+				"v1.29.6+k3s1":  false,
+				"v1.30.2+k3s1":  true,
+			},
+		},
+		"bare version and exclusion": {
+			constraint: "v1.29.6 || !=v1.29.5 >v1.29.0",
+			versions: map[string]bool{
+				"v1.29.6+k3s1": true,
+				"v1.29.5+k3s1": false,
+				"v1.29.4+k3s1": true,
+				"v1.28.4+k3s1": false,
+			},
+		},
+		"range with k3s revisions": {
+			constraint: "<=v1.29.4 || =v1.30.1+k3s2",
+			versions: map[string]bool{
+				"v1.29.4+k3s2": true,
+				"v1.29.5+k3s1": false,
+				"v1.30.1+k3s1": false,
+				"v1.30.1+k3s2": true,
+			},
+		},
+		"error case with invalid version": {
+			constraint:  ">=some-version",
+			expectError: true,
+		},
+		"error case with empty alternative": {
+			constraint:  ">=v1.29.0 ||",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			constraint, err := parseVersionConstraint(c.constraint)
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for version, expected := range c.versions {
+				if got := constraint.check(version); got != expected {
+					t.Errorf("expected check(%q) to be %t, got %t", version, expected, got)
+				}
+			}
+		})
+	}
+}
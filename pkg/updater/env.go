@@ -0,0 +1,178 @@
+package updater
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// defaultEnvKeys are the variables pinning the
+// k3s version in env files, scripts and
+// Dockerfiles, unless a version key is set.
+var defaultEnvKeys = []string{"INSTALL_K3S_VERSION", "K3S_VERSION"}
+
+// envFileManager updates KEY=value assignments of
+// env files, shell scripts (i.e.: cloud-init) and
+// Dockerfile ARG/ENV instructions, line by line.
+type envFileManager struct{}
+
+// envAssignment is the value of an
+// assignment located in the source.
+type envAssignment struct {
+	key   string
+	value string
+
+	// start and end are the byte offsets of
+	// the value in the source, quotes excluded.
+	start int
+	end   int
+}
+
+// envKeys
+//
+// Returns the variables to update.
+func envKeys(req UpdateReleaseReq) []string {
+	if req.VersionKey == "" {
+		return defaultEnvKeys
+	}
+	return []string{req.VersionKey}
+}
+
+// envAssignments
+//
+// Returns the assignments of the given variables, or of
+// every variable when none is given. Assignments are
+// KEY=value words of a line (i.e.: "export KEY=value",
+// "ARG KEY=value", "KEY=value sh -") or legacy "ENV KEY
+// value" Dockerfile instructions. Commented lines are
+// ignored, and so are values referencing other
+// variables, which don't pin anything themselves.
+func envAssignments(content []byte, keys []string) []envAssignment {
+	name := `[A-Za-z_][A-Za-z0-9_]*`
+	if len(keys) > 0 {
+		quoted := make([]string, 0, len(keys))
+		for _, key := range keys {
+			quoted = append(quoted, regexp.QuoteMeta(key))
+		}
+		name = strings.Join(quoted, "|")
+	}
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`(?:^|[\s;&|(])(` + name + `)=`),
+		regexp.MustCompile(`(?mi)^[ \t]*ENV[ \t]+(` + name + `)[ \t]+`),
+	}
+
+	assignments := make([]envAssignment, 0)
+	for _, pattern := range patterns {
+		for _, match := range pattern.FindAllSubmatchIndex(content, -1) {
+			if isEnvComment(content, match[0]) {
+				continue
+			}
+
+			assignment := readEnvValue(content, match[1])
+			if strings.Contains(assignment.value, "$") {
+				continue
+			}
+			assignment.key = string(content[match[2]:match[3]])
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	return assignments
+}
+
+// isEnvComment
+//
+// Reports whether an offset is
+// on a commented line.
+func isEnvComment(content []byte, offset int) bool {
+	lineStart := bytes.LastIndexByte(content[:offset], '\n') + 1
+	return strings.HasPrefix(strings.TrimSpace(string(content[lineStart:offset])), "#")
+}
+
+// readEnvValue
+//
+// Reads the value starting at an offset,
+// which is quoted or ends with the word.
+func readEnvValue(content []byte, start int) envAssignment {
+	if start < len(content) && (content[start] == '"' || content[start] == '\'') {
+		quote := content[start]
+		end := start + 1
+		for end < len(content) && content[end] != quote && content[end] != '\n' {
+			if quote == '"' && content[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end = min(end, len(content))
+		return envAssignment{value: string(content[start+1 : end]), start: start + 1, end: end}
+	}
+
+	end := start
+	for end < len(content) && !strings.ContainsRune(" \t\r\n;&|)#", rune(content[end])) {
+		end++
+	}
+
+	return envAssignment{value: string(content[start:end]), start: start, end: end}
+}
+
+// isEnvFile
+//
+// Reports whether a file name is the one
+// of an env file, a script or a Dockerfile.
+func isEnvFile(filePath string) bool {
+	name := strings.ToLower(path.Base(filePath))
+	switch {
+	case name == "dockerfile", name == "containerfile", name == ".envrc":
+		return true
+	case strings.HasPrefix(name, "dockerfile."), strings.HasPrefix(name, ".env"):
+		return true
+	}
+
+	switch path.Ext(name) {
+	case ".env", ".sh", ".bash", ".dockerfile":
+		return true
+	}
+
+	return false
+}
+
+func (envFileManager) extract(req UpdateReleaseReq, content []byte) (string, error) {
+	assignments := envAssignments(content, envKeys(req))
+	if len(assignments) == 0 {
+		return "", fmt.Errorf("no %s assignment found", strings.Join(envKeys(req), " or "))
+	}
+
+	versions := make([]string, 0, len(assignments))
+	for _, assignment := range assignments {
+		versions = append(versions, assignment.value)
+	}
+
+	return oldestK3sVersion(versions), nil
+}
+
+func (envFileManager) update(req updateFileReq) ([]byte, error) {
+	content := []byte(req.fileContent)
+	assignments := envAssignments(content, envKeys(req.UpdateReleaseReq))
+	if len(assignments) == 0 {
+		return nil, fmt.Errorf("no %s assignment found", strings.Join(envKeys(req.UpdateReleaseReq), " or "))
+	}
+
+	replacements := make([]replacement, 0, len(assignments))
+	for _, assignment := range assignments {
+		replacements = append(replacements, replacement{start: assignment.start, end: assignment.end, value: *req.latestRelease.Name})
+	}
+
+	return replaceRanges(content, replacements), nil
+}
+
+func (envFileManager) scan(_ string, content []byte) []versionPin {
+	pins := make([]versionPin, 0)
+	for _, assignment := range envAssignments(content, nil) {
+		if looksLikeK3sVersion(assignment.key, assignment.value) {
+			pins = append(pins, versionPin{key: assignment.key, version: assignment.value})
+		}
+	}
+	return pins
+}
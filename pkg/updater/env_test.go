@@ -0,0 +1,119 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestEnvFileManager(t *testing.T) {
+	cases := map[string]struct {
+		versionKey string
+		content    string
+
+		expectedVersion string
+		expectedContent string
+		expectError     bool
+	}{
+		"success case with an env file": {
+			content:         "K3S_TOKEN=some-token\nINSTALL_K3S_VERSION=v1.28.5+k3s1\n",
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: "K3S_TOKEN=some-token\nINSTALL_K3S_VERSION=v1.29.6+k3s2\n",
+		},
+		"success case with quoted exports": {
+			content:         "#!/bin/sh\nexport INSTALL_K3S_VERSION=\"v1.28.5+k3s1\"\nexport K3S_VERSION='v1.28.5+k3s1' # same one\r\n",
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: "#!/bin/sh\nexport INSTALL_K3S_VERSION=\"v1.29.6+k3s2\"\nexport K3S_VERSION='v1.29.6+k3s2' # same one\r\n",
+		},
+		"success case with an install command": {
+			content: `#cloud-config
+runcmd:
+  - curl -sfL https://get.k3s.io | INSTALL_K3S_VERSION=v1.28.5+k3s1 sh -s - server
+`,
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: `#cloud-config
+runcmd:
+  - curl -sfL https://get.k3s.io | INSTALL_K3S_VERSION=v1.29.6+k3s2 sh -s - server
+`,
+		},
+		"success case with a dockerfile": {
+			content: `FROM alpine:3.19
+# ARG K3S_VERSION=v1.27.1+k3s1
+ARG K3S_VERSION=v1.28.5+k3s1
+ENV INSTALL_K3S_VERSION=${K3S_VERSION}
+ENV INSTALL_K3S_VERSION_LEGACY v1.20.0+k3s1
+RUN curl -sfL https://get.k3s.io | INSTALL_K3S_VERSION=$K3S_VERSION sh -
+`,
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: `FROM alpine:3.19
+# ARG K3S_VERSION=v1.27.1+k3s1
+ARG K3S_VERSION=v1.29.6+k3s2
+ENV INSTALL_K3S_VERSION=${K3S_VERSION}
+ENV INSTALL_K3S_VERSION_LEGACY v1.20.0+k3s1
+RUN curl -sfL https://get.k3s.io | INSTALL_K3S_VERSION=$K3S_VERSION sh -
+`,
+		},
+		"success case with a legacy env instruction": {
+			content:         "FROM alpine:3.19\nENV K3S_VERSION v1.28.5+k3s1\n",
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: "FROM alpine:3.19\nENV K3S_VERSION v1.29.6+k3s2\n",
+		},
+		"success case with drifted versions": {
+			content:         "INSTALL_K3S_VERSION=v1.28.5+k3s1\nK3S_VERSION=v1.28.4+k3s2\n",
+			expectedVersion: "v1.28.4+k3s2",
+			expectedContent: "INSTALL_K3S_VERSION=v1.29.6+k3s2\nK3S_VERSION=v1.29.6+k3s2\n",
+		},
+		"success case with a version key": {
+			versionKey:      "K3S_RELEASE",
+			content:         "K3S_VERSION=v1.20.0+k3s1\nK3S_RELEASE=v1.28.5+k3s1\n",
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: "K3S_VERSION=v1.20.0+k3s1\nK3S_RELEASE=v1.29.6+k3s2\n",
+		},
+		"error case with commented assignments only": {
+			content:     "# INSTALL_K3S_VERSION=v1.28.5+k3s1\n",
+			expectError: true,
+		},
+		"error case with variable references only": {
+			content:     "INSTALL_K3S_VERSION=\"${VERSION}\"\n",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := envFileManager{}
+			req := UpdateReleaseReq{VersionKey: c.versionKey}
+
+			version, err := m.extract(req, []byte(c.content))
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if version != c.expectedVersion {
+				t.Fatalf("expected version %q, got %q", c.expectedVersion, version)
+			}
+
+			updated, err := m.update(updateFileReq{
+				fileContent: c.content,
+				latestRelease: &github.RepositoryRelease{
+					Name: github.String("v1.29.6+k3s2"),
+				},
+				UpdateReleaseReq: req,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(updated) != c.expectedContent {
+				t.Fatalf("expected:\n%s\ngot:\n%s", c.expectedContent, updated)
+			}
+		})
+	}
+}
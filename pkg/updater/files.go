@@ -0,0 +1,243 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/cguertin14/k3supdater/pkg/platform"
+	"github.com/cguertin14/logger"
+	"github.com/google/go-github/v57/github"
+)
+
+// versionFile is a file of the
+// repository holding the k3s version.
+type versionFile struct {
+	path           string
+	content        string
+	currentVersion string
+	manager        manager
+
+	// vault is the envelope of the file when it
+	// is encrypted with ansible-vault, in which
+	// case content is the decrypted content.
+	vault *vaultEnvelope
+
+	// updatedContent is the content of the
+	// file once updated to latestRelease.
+	updatedContent []byte
+
+	// latestRelease is the release to update the
+	// file to, with no name if it's up to date.
+	latestRelease *github.RepositoryRelease
+}
+
+// forPath
+//
+// Returns a copy of the request
+// targeting a single file.
+func (req UpdateReleaseReq) forPath(path string) UpdateReleaseReq {
+	req.Repo.Path = path
+	return req
+}
+
+// readVersionFile
+//
+// Fetches a file to update, decrypting
+// it when encrypted with ansible-vault.
+func (c *ClientSet) readVersionFile(ctx context.Context, req UpdateReleaseReq) (*versionFile, error) {
+	fileContent, err := c.getGroupVarsFileContent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	content, envelope, err := openVault(req, fileContent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &versionFile{
+		path:    req.Repo.Path,
+		content: content,
+		vault:   envelope,
+	}, nil
+}
+
+// listFiles
+//
+// Returns every file of the repository branch.
+func (c *ClientSet) listFiles(ctx context.Context, repo Repository) ([]string, error) {
+	paths, err := c.platform.ListFiles(ctx, platform.ListFilesRequest{
+		Repository: repo.platform(),
+		Branch:     repo.Branch,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error when listing files of %s/%s: %s", repo.Owner, repo.Name, err)
+	}
+
+	return paths, nil
+}
+
+// resolvePaths
+//
+// Returns the files to update, from the configured
+// paths and glob patterns. Patterns are matched
+// against the tree of the repository branch.
+func (c *ClientSet) resolvePaths(ctx context.Context, req UpdateReleaseReq) ([]string, error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+
+	literals, patterns := make([]string, 0), make([]string, 0)
+	for _, p := range append([]string{req.Repo.Path}, req.Repo.Paths...) {
+		_, filePath := splitManager(p)
+		switch {
+		case filePath == "":
+			continue
+		case isGlob(filePath):
+			if _, err := path.Match(filePath, ""); err != nil {
+				return nil, fmt.Errorf("error when parsing pattern %q: %s", filePath, err)
+			}
+			patterns = append(patterns, p)
+		default:
+			literals = append(literals, p)
+		}
+	}
+
+	paths := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(p string) {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	for _, p := range literals {
+		add(p)
+	}
+
+	if len(patterns) > 0 {
+		files, err := c.listFiles(ctx, req.Repo)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pattern := range patterns {
+			// Matches keep the manager
+			// prefix of their pattern.
+			name, filePattern := splitManager(pattern)
+			matches := make([]string, 0)
+			for _, filePath := range files {
+				if matchGlob(filePattern, filePath) {
+					match := filePath
+					if name != "" {
+						match = name + ":" + match
+					}
+					matches = append(matches, match)
+				}
+			}
+			if len(matches) == 0 {
+				logger.Warnf("No file matches %q in %s/%s.", pattern, req.Repo.Owner, req.Repo.Name)
+			}
+
+			sort.Strings(matches)
+			for _, match := range matches {
+				add(match)
+			}
+		}
+	}
+
+	// Inventories are enough on their own
+	if len(paths) == 0 && len(req.Repo.Inventories) == 0 {
+		return nil, fmt.Errorf("error when resolving files: no file to update in %s/%s", req.Repo.Owner, req.Repo.Name)
+	}
+
+	return paths, nil
+}
+
+func isGlob(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// matchGlob
+//
+// Reports whether a file path matches a pattern, using
+// path.Match for each path segment. A "**" segment
+// matches any number of directories.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], name[0]); !matched {
+		return false
+	}
+
+	return matchSegments(pattern[1:], name[1:])
+}
+
+// filesNote
+//
+// Summarizes the version change of
+// every file in a markdown table.
+func filesNote(files []*versionFile) string {
+	lines := []string{
+		"| File | Current version | New version |",
+		"| --- | --- | --- |",
+	}
+	for _, f := range files {
+		newVersion := "up to date"
+		if f.latestRelease.GetName() != "" {
+			newVersion = fmt.Sprintf("`%s`", f.latestRelease.GetName())
+		}
+		lines = append(lines, fmt.Sprintf("| `%s` | `%s` | %s |", f.path, f.currentVersion, newVersion))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// replacement is a value replacing the bytes
+// between two offsets of a file content.
+type replacement struct {
+	start int
+	end   int
+	value string
+}
+
+// replaceRanges
+//
+// Applies replacements to a file content, their
+// offsets being the ones of the original content.
+func replaceRanges(content []byte, replacements []replacement) []byte {
+	// Replace from the end of the source, so
+	// that offsets left to replace stay valid.
+	sorted := slices.Clone(replacements)
+	slices.SortFunc(sorted, func(a, b replacement) int {
+		return b.start - a.start
+	})
+
+	updated := slices.Clone(content)
+	for _, r := range sorted {
+		tail := updated[r.end:]
+		updated = append(append(updated[:r.start:r.start], r.value...), tail...)
+	}
+
+	return updated
+}
@@ -0,0 +1,325 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	github_mocks "github.com/cguertin14/k3supdater/pkg/github/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v57/github"
+)
+
+func TestMatchGlob(t *testing.T) {
+	cases := map[string]struct {
+		pattern string
+		name    string
+
+		expected bool
+	}{
+		"match with a single wildcard": {
+			pattern:  "inventory/*/group_vars/all.yml",
+			name:     "inventory/prod/group_vars/all.yml",
+			expected: true,
+		},
+		"no match with a single wildcard across directories": {
+			pattern: "inventory/*/all.yml",
+			name:    "inventory/prod/group_vars/all.yml",
+		},
+		"match with a double wildcard": {
+			pattern:  "inventory/**/all.yml",
+			name:     "inventory/prod/group_vars/all.yml",
+			expected: true,
+		},
+		"match with a double wildcard and no directory": {
+			pattern:  "inventory/**/all.yml",
+			name:     "inventory/all.yml",
+			expected: true,
+		},
+		"match with a trailing double wildcard": {
+			pattern:  "inventory/**",
+			name:     "inventory/lab/group_vars/k3s.yml",
+			expected: true,
+		},
+		"no match with another file name": {
+			pattern: "inventory/*/group_vars/all.yml",
+			name:    "inventory/prod/group_vars/other.yml",
+		},
+		"match with a character class": {
+			pattern:  "inventory/*/group_vars/all.y[a]ml",
+			name:     "inventory/prod/group_vars/all.yaml",
+			expected: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if matched := matchGlob(c.pattern, c.name); matched != c.expected {
+				t.Fatalf("expected %t, got %t", c.expected, matched)
+			}
+		})
+	}
+}
+
+func TestResolvePaths(t *testing.T) {
+	tree := &github.Tree{
+		Entries: []*github.TreeEntry{
+			{Path: github.String("inventory"), Type: github.String("tree")},
+			{Path: github.String("inventory/staging/group_vars/all.yml"), Type: github.String("blob")},
+			{Path: github.String("inventory/prod/group_vars/all.yml"), Type: github.String("blob")},
+			{Path: github.String("inventory/prod/group_vars/other.yml"), Type: github.String("blob")},
+			{Path: github.String("inventory/lab/group_vars/all.yml"), Type: github.String("blob")},
+		},
+	}
+
+	cases := map[string]struct {
+		path    string
+		paths   []string
+		treeErr error
+
+		expectTree    bool
+		expectedPaths []string
+		expectError   bool
+	}{
+		"success case with a single path": {
+			path:          "inventory/prod/group_vars/all.yml",
+			expectedPaths: []string{"inventory/prod/group_vars/all.yml"},
+		},
+		"success case with a pattern": {
+			paths:      []string{"inventory/*/group_vars/all.yml"},
+			expectTree: true,
+			expectedPaths: []string{
+				"inventory/lab/group_vars/all.yml",
+				"inventory/prod/group_vars/all.yml",
+				"inventory/staging/group_vars/all.yml",
+			},
+		},
+		"success case with duplicate paths": {
+			path:       "inventory/prod/group_vars/all.yml",
+			paths:      []string{"inventory/*/group_vars/all.yml", "inventory/prod/group_vars/all.yml"},
+			expectTree: true,
+			expectedPaths: []string{
+				"inventory/prod/group_vars/all.yml",
+				"inventory/lab/group_vars/all.yml",
+				"inventory/staging/group_vars/all.yml",
+			},
+		},
+		"success case with a manager prefix": {
+			paths:      []string{"plan:inventory/prod/group_vars/*.yml"},
+			expectTree: true,
+			expectedPaths: []string{
+				"plan:inventory/prod/group_vars/all.yml",
+				"plan:inventory/prod/group_vars/other.yml",
+			},
+		},
+		"error case with no match": {
+			paths:       []string{"other/*.yml"},
+			expectTree:  true,
+			expectError: true,
+		},
+		"error case with tree error": {
+			paths:       []string{"inventory/*/group_vars/all.yml"},
+			treeErr:     errors.New("some error"),
+			expectTree:  true,
+			expectError: true,
+		},
+		"error case with invalid pattern": {
+			paths:       []string{"inventory/[/all.yml"},
+			expectError: true,
+		},
+		"error case with no path": {
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// create new mock client instance
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+
+			// define mock behavior
+			if c.expectTree {
+				githubMockClient.EXPECT().GetTree(gomock.Any(), legacy.GetTreeRequest{
+					Owner:     "some owner",
+					Repo:      "some name",
+					SHA:       "main",
+					Recursive: true,
+				}).
+					Times(1).
+					Return(tree, nil, c.treeErr)
+			}
+
+			// create mock updater client
+			client := NewClient(context.Background(), Dependencies{
+				Client: githubMockClient,
+			})
+
+			paths, err := client.resolvePaths(context.Background(), UpdateReleaseReq{
+				Repo: Repository{
+					Owner:  "some owner",
+					Name:   "some name",
+					Path:   c.path,
+					Paths:  c.paths,
+					Branch: "main",
+				},
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(paths, c.expectedPaths) {
+				t.Fatalf("expected %v, got %v", c.expectedPaths, paths)
+			}
+		})
+	}
+}
+
+func TestUpdateK3sReleaseMultipleFiles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	files := map[string]string{
+		"inventory/prod/group_vars/all.yml":    "v1.23.3",
+		"inventory/staging/group_vars/all.yml": "v1.22.3",
+		"inventory/lab/group_vars/all.yml":     "v1.23.4",
+	}
+
+	// create new mock client instance
+	githubMockClient := github_mocks.NewMockClient(ctrl)
+
+	// define mock behavior
+	githubMockClient.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(&github.Tree{
+			Entries: []*github.TreeEntry{
+				{Path: github.String("inventory/lab/group_vars/all.yml"), Type: github.String("blob")},
+				{Path: github.String("inventory/staging/group_vars/all.yml"), Type: github.String("blob")},
+			},
+		}, nil, nil)
+	githubMockClient.EXPECT().GetRepositoryContents(gomock.Any(), gomock.Any()).
+		Times(len(files)).
+		DoAndReturn(func(_ context.Context, req legacy.GetRepositoryContentsRequest) (*github.RepositoryContent, *github.RepositoryContent, *github.Response, error) {
+			return &github.RepositoryContent{
+				Content: github.String(
+					base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s: %s", DefaultVersionKey, files[req.Path]))),
+				),
+			}, nil, nil, nil
+		})
+	githubMockClient.EXPECT().GetRepositoryReleases(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]*github.RepositoryRelease{
+			{Name: github.String("v1.23.4"), Body: github.String("some release notes")},
+			{Name: github.String("v1.22.5"), Body: github.String("some release notes")},
+			{Name: github.String("v1.22.3"), Body: github.String("some release notes")},
+		}, nil, nil)
+	githubMockClient.EXPECT().GetBranch(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(&github.Reference{
+			Object: &github.GitObject{},
+		}, nil, nil)
+	githubMockClient.EXPECT().CreateBranch(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(nil, nil, nil)
+
+	updated := make(map[string]string)
+	githubMockClient.EXPECT().GetCommit(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(&github.Commit{Tree: &github.Tree{}}, nil, nil)
+	// the base tree, for the modes of the committed files
+	githubMockClient.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(&github.Tree{}, nil, nil)
+	githubMockClient.EXPECT().CreateBlob(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ context.Context, req legacy.CreateBlobRequest) (*github.Blob, *github.Response, error) {
+			content, _ := base64.StdEncoding.DecodeString(req.GetContent())
+			return &github.Blob{SHA: github.String(string(content))}, nil, nil
+		})
+	githubMockClient.EXPECT().CreateTree(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, req legacy.CreateTreeRequest) (*github.Tree, *github.Response, error) {
+			for _, entry := range req.Entries {
+				updated[entry.GetPath()] = entry.GetSHA()
+			}
+			return &github.Tree{}, nil, nil
+		})
+	githubMockClient.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(&github.Commit{}, nil, nil)
+	githubMockClient.EXPECT().UpdateRef(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, req legacy.UpdateRefRequest) (*github.Reference, *github.Response, error) {
+			if req.GetRef() != "refs/heads/release/k3s-v1.23.4-update" {
+				t.Errorf("unexpected branch %q", req.GetRef())
+			}
+			return nil, nil, nil
+		})
+
+	var pr *github.NewPullRequest
+	githubMockClient.EXPECT().CreatePullRequest(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, req legacy.CreatePRRequest) (*github.PullRequest, *github.Response, error) {
+			pr = req.NewPullRequest
+			return nil, nil, nil
+		})
+
+	// create mock updater client
+	client := NewClient(context.Background(), Dependencies{
+		Client: githubMockClient,
+	})
+
+	if err := client.UpdateK3sRelease(context.Background(), UpdateReleaseReq{
+		Repo: Repository{
+			Owner:  "some owner",
+			Name:   "some name",
+			Path:   "inventory/prod/group_vars/all.yml",
+			Paths:  []string{"inventory/*/group_vars/all.yml"},
+			Branch: "main",
+		},
+		ReleaseRepo: Repository{
+			Owner: "k3s-io",
+			Name:  "k3s",
+		},
+		UpdatePolicy: UpdatePolicyPatch,
+	}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expectedUpdates := map[string]string{
+		"inventory/prod/group_vars/all.yml":    fmt.Sprintf("%s: %s", DefaultVersionKey, "v1.23.4"),
+		"inventory/staging/group_vars/all.yml": fmt.Sprintf("%s: %s", DefaultVersionKey, "v1.22.5"),
+	}
+	if !reflect.DeepEqual(updated, expectedUpdates) {
+		t.Fatalf("expected updates %v, got %v", expectedUpdates, updated)
+	}
+
+	expectedTitle := "new release: k3s update from v1.23.3, v1.22.3 to v1.23.4, v1.22.5"
+	if pr.GetTitle() != expectedTitle {
+		t.Fatalf("expected title %q, got %q", expectedTitle, pr.GetTitle())
+	}
+	for _, row := range []string{
+		"| `inventory/prod/group_vars/all.yml` | `v1.23.3` | `v1.23.4` |",
+		"| `inventory/lab/group_vars/all.yml` | `v1.23.4` | up to date |",
+		"| `inventory/staging/group_vars/all.yml` | `v1.22.3` | `v1.22.5` |",
+	} {
+		if !strings.Contains(pr.GetBody(), row) {
+			t.Fatalf("expected body to contain %q, got %q", row, pr.GetBody())
+		}
+	}
+}
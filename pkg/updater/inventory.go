@@ -0,0 +1,243 @@
+package updater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/cguertin14/logger"
+	"github.com/google/go-github/v57/github"
+)
+
+// Variable scopes of an ansible inventory,
+// from the lowest to the highest precedence.
+const (
+	inventoryScopeAll = iota
+	inventoryScopeGroup
+	inventoryScopeHost
+)
+
+// inventoryScope
+//
+// Returns the scope of a variable file of an inventory,
+// which is either a file or a file of a directory named
+// after a group or a host (i.e.: group_vars/all.yml,
+// group_vars/all/main.yml or host_vars/node1).
+func inventoryScope(inventory, filePath string) (scope int, ok bool) {
+	rel, found := filePath, true
+	if inventory = strings.TrimSuffix(inventory, "/"); inventory != "" {
+		rel, found = strings.CutPrefix(filePath, inventory+"/")
+	}
+	if !found {
+		return 0, false
+	}
+
+	dir, rest, found := strings.Cut(rel, "/")
+	if !found || rest == "" {
+		return 0, false
+	}
+	name, _, _ := strings.Cut(rest, "/")
+
+	switch dir {
+	case "group_vars":
+		if strings.TrimSuffix(name, path.Ext(name)) == "all" {
+			return inventoryScopeAll, true
+		}
+		return inventoryScopeGroup, true
+	case "host_vars":
+		return inventoryScopeHost, true
+	default:
+		return 0, false
+	}
+}
+
+// isInventoryVarsFile
+//
+// Reports whether an inventory file may hold
+// variables, which is the case of yaml and json
+// files, or files with no extension.
+func isInventoryVarsFile(filePath string) bool {
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".yml", ".yaml", ".json", "":
+		return true
+	default:
+		return false
+	}
+}
+
+// inventoryCache holds the variable
+// files of the inventories read in a
+// run, by inventory directory.
+type inventoryCache map[string][]*versionFile
+
+// inventoryOf
+//
+// Returns the inventory directory of a group_vars
+// or host_vars file, which is empty for the root
+// of the repository.
+func inventoryOf(filePath string) (inventory string, ok bool) {
+	for _, dir := range []string{"group_vars", "host_vars"} {
+		if i := strings.Index("/"+filePath, "/"+dir+"/"); i >= 0 {
+			return strings.TrimSuffix(filePath[:i], "/"), true
+		}
+	}
+	return "", false
+}
+
+// inventoryFiles
+//
+// Returns every group_vars and host_vars file of an
+// inventory, from the lowest to the highest precedence.
+// In the same scope, files are ordered by path, the way
+// ansible loads them. Files are only read once a run.
+func (c *ClientSet) inventoryFiles(ctx context.Context, req UpdateReleaseReq, inventory string, cache inventoryCache) ([]*versionFile, error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+	if files, ok := cache[inventory]; ok {
+		return files, nil
+	}
+
+	paths, err := c.listFiles(ctx, req.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*versionFile, 0)
+	scopes := make(map[string]int)
+	for _, filePath := range paths {
+		scope, ok := inventoryScope(inventory, filePath)
+		if !ok || !isInventoryVarsFile(filePath) {
+			continue
+		}
+
+		f, err := c.readVersionFile(ctx, req.forPath(filePath))
+		if errors.Is(err, errNoVaultPassword) {
+			logger.Warnf("Skipping %q: %s", filePath, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		f.manager = managers[ansibleManager]
+		scopes[f.path] = scope
+		files = append(files, f)
+	}
+
+	slices.SortStableFunc(files, func(a, b *versionFile) int {
+		if scopes[a.path] != scopes[b.path] {
+			return scopes[a.path] - scopes[b.path]
+		}
+		return strings.Compare(a.path, b.path)
+	})
+
+	cache[inventory] = files
+	return files, nil
+}
+
+// inventoryDefinitions
+//
+// Returns the variable files of an inventory
+// defining the version key, by precedence.
+func (c *ClientSet) inventoryDefinitions(ctx context.Context, req UpdateReleaseReq, inventory string, cache inventoryCache) ([]*versionFile, error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+
+	files, err := c.inventoryFiles(ctx, req, inventory, cache)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions := make([]*versionFile, 0)
+	for _, file := range files {
+		// Files are shared with templates,
+		// which may reference their variables.
+		f := *file
+		if err := c.resolveTemplate(ctx, req.forPath(f.path), &f, cache); err != nil {
+			return nil, err
+		}
+
+		f.currentVersion, err = f.manager.extract(req.forPath(f.path), []byte(f.content))
+		if err != nil {
+			// Most files don't define the
+			// version, which is expected.
+			if !errors.Is(err, errKeyNotFound) {
+				logger.Warnf("Skipping %q: %s", f.path, err)
+			}
+			continue
+		}
+
+		definitions = append(definitions, &f)
+	}
+
+	return definitions, nil
+}
+
+// getInventoryRelease
+//
+// Picks a single new version for every definition
+// of the version key in an inventory, so that they
+// stay consistent. The oldest definition is the one
+// the new version is picked for, so that the update
+// policy holds for every host. Definitions already
+// at, or past, the new version are left as is.
+func (c *ClientSet) getInventoryRelease(ctx context.Context, req UpdateReleaseReq, inventory string, definitions []*versionFile, releases []*github.RepositoryRelease) (notes []string, err error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+	if len(definitions) == 0 {
+		return nil, fmt.Errorf("error when reading inventory %q: %q is not defined in any group_vars or host_vars file", inventory, req.versionKey())
+	}
+
+	oldest := definitions[0]
+	versions := []string{oldest.currentVersion}
+	for _, d := range definitions[1:] {
+		if compareK3sVersions(d.currentVersion, oldest.currentVersion) < 0 {
+			oldest = d
+		}
+		if !slices.Contains(versions, d.currentVersion) {
+			versions = append(versions, d.currentVersion)
+		}
+	}
+	if len(versions) > 1 {
+		logger.Warnf("%q is defined with different versions in inventory %q: %s", req.versionKey(), inventory, strings.Join(versions, ", "))
+		notes = append(notes, inventoryNote(req.versionKey(), inventory, definitions))
+	}
+
+	latestRelease, _, releaseNotes, err := c.getLatestK3sRelease(ctx, getLatestK3sReleaseRequest{
+		UpdateReleaseReq: req.forPath(oldest.path),
+		fileContent:      oldest.content,
+		manager:          oldest.manager,
+		releases:         releases,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range definitions {
+		d.latestRelease = &github.RepositoryRelease{}
+		if latestRelease.Name != nil && compareK3sVersions(d.currentVersion, *latestRelease.Name) < 0 {
+			d.latestRelease = latestRelease
+		}
+	}
+
+	return append(notes, releaseNotes...), nil
+}
+
+// inventoryNote
+//
+// Lists the conflicting definitions of the
+// version key in an inventory, in a markdown
+// table ordered by precedence.
+func inventoryNote(key, inventory string, definitions []*versionFile) string {
+	lines := []string{
+		fmt.Sprintf("**`%s` is defined with different versions in `%s`**, listed below from the lowest to the highest precedence. Every definition older than the new version is updated to it.", key, inventory),
+		"",
+		"| File | Current version |",
+		"| --- | --- |",
+	}
+	for _, d := range definitions {
+		lines = append(lines, fmt.Sprintf("| `%s` | `%s` |", d.path, d.currentVersion))
+	}
+
+	return strings.Join(lines, "\n")
+}
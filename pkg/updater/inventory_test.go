@@ -0,0 +1,234 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	github_mocks "github.com/cguertin14/k3supdater/pkg/github/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v57/github"
+)
+
+// inventoryFixture is the repository holding
+// the inventories, by file path.
+var inventoryFixture = map[string]string{
+	"inventory/prod/host_vars/node1.yml":     "k3s_release_version: v1.23.4\n",
+	"inventory/prod/host_vars/node2":         "ansible_host: 10.0.0.2\n",
+	"inventory/prod/group_vars/masters.yml":  "k3s_release_version: v1.23.3\n",
+	"inventory/prod/group_vars/all/main.yml": "k3s_release_version: v1.22.3\n",
+	"inventory/prod/hosts.ini":               "[masters]\nnode1\n",
+	"inventory/lab/group_vars/all.yml":       "k3s_release_version: v1.21.0\n",
+}
+
+func TestInventoryScope(t *testing.T) {
+	cases := map[string]struct {
+		filePath string
+
+		expectedScope int
+		expectedOk    bool
+	}{
+		"all group file": {
+			filePath:      "inventory/prod/group_vars/all.yml",
+			expectedScope: inventoryScopeAll,
+			expectedOk:    true,
+		},
+		"all group directory": {
+			filePath:      "inventory/prod/group_vars/all/main.yml",
+			expectedScope: inventoryScopeAll,
+			expectedOk:    true,
+		},
+		"other group": {
+			filePath:      "inventory/prod/group_vars/masters.yml",
+			expectedScope: inventoryScopeGroup,
+			expectedOk:    true,
+		},
+		"host file with no extension": {
+			filePath:      "inventory/prod/host_vars/node1",
+			expectedScope: inventoryScopeHost,
+			expectedOk:    true,
+		},
+		"inventory file": {
+			filePath: "inventory/prod/hosts.ini",
+		},
+		"other inventory": {
+			filePath: "inventory/production/group_vars/all.yml",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			scope, ok := inventoryScope("inventory/prod/", c.filePath)
+			if scope != c.expectedScope || ok != c.expectedOk {
+				t.Fatalf("expected (%d, %t), got (%d, %t)", c.expectedScope, c.expectedOk, scope, ok)
+			}
+		})
+	}
+}
+
+func TestUpdateK3sReleaseInventory(t *testing.T) {
+	cases := map[string]struct {
+		inventory string
+		treeError error
+
+		expectedUpdates map[string]string
+		expectedNote    []string
+		expectError     bool
+	}{
+		"success case with conflicting definitions": {
+			inventory: "inventory/prod",
+			expectedUpdates: map[string]string{
+				"inventory/prod/group_vars/all/main.yml": "k3s_release_version: v1.23.4\n",
+				"inventory/prod/group_vars/masters.yml":  "k3s_release_version: v1.23.4\n",
+			},
+			expectedNote: []string{
+				"| `inventory/prod/group_vars/all/main.yml` | `v1.22.3` |",
+				"| `inventory/prod/group_vars/masters.yml` | `v1.23.3` |",
+				"| `inventory/prod/host_vars/node1.yml` | `v1.23.4` |",
+			},
+		},
+		"error case with no definition": {
+			inventory:   "inventory/staging",
+			expectError: true,
+		},
+		"error case with tree error": {
+			inventory:   "inventory/prod",
+			treeError:   errors.New("some error"),
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// create new mock client instance
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+
+			// define mock behavior
+			entries := make([]*github.TreeEntry, 0)
+			for filePath := range inventoryFixture {
+				entries = append(entries, &github.TreeEntry{Path: github.String(filePath), Type: github.String("blob")})
+			}
+			githubMockClient.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(&github.Tree{Entries: entries}, nil, c.treeError)
+			githubMockClient.EXPECT().GetRepositoryContents(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				DoAndReturn(func(_ context.Context, req legacy.GetRepositoryContentsRequest) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+					if strings.HasSuffix(req.Path, ".ini") || strings.HasPrefix(req.Path, "inventory/lab/") {
+						t.Errorf("unexpected read of %q", req.Path)
+					}
+					return &github.RepositoryContent{
+						Content: github.String(base64.StdEncoding.EncodeToString([]byte(inventoryFixture[req.Path]))),
+					}, nil, nil, nil
+				})
+			githubMockClient.EXPECT().GetRepositoryReleases(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return([]*github.RepositoryRelease{
+					{Name: github.String("v1.23.4"), Body: github.String("some release notes")},
+					{Name: github.String("v1.22.5"), Body: github.String("some release notes")},
+					{Name: github.String("v1.22.3"), Body: github.String("some release notes")},
+				}, nil, nil)
+
+			updated := make(map[string]string)
+			var pr *github.NewPullRequest
+			if !c.expectError {
+				githubMockClient.EXPECT().GetBranch(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(&github.Reference{
+						Object: &github.GitObject{},
+					}, nil, nil)
+				githubMockClient.EXPECT().CreateBranch(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, nil, nil)
+				githubMockClient.EXPECT().GetCommit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Commit{Tree: &github.Tree{}}, nil, nil)
+				// the base tree, for the modes of the committed files
+				githubMockClient.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Tree{}, nil, nil)
+				githubMockClient.EXPECT().CreateBlob(gomock.Any(), gomock.Any()).
+					Times(len(c.expectedUpdates)).
+					DoAndReturn(func(_ context.Context, req legacy.CreateBlobRequest) (*github.Blob, *github.Response, error) {
+						content, _ := base64.StdEncoding.DecodeString(req.GetContent())
+						return &github.Blob{SHA: github.String(string(content))}, nil, nil
+					})
+				githubMockClient.EXPECT().CreateTree(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, req legacy.CreateTreeRequest) (*github.Tree, *github.Response, error) {
+						for _, entry := range req.Entries {
+							updated[entry.GetPath()] = entry.GetSHA()
+						}
+						return &github.Tree{}, nil, nil
+					})
+				githubMockClient.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Commit{}, nil, nil)
+				githubMockClient.EXPECT().UpdateRef(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, nil, nil)
+				githubMockClient.EXPECT().CreatePullRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, req legacy.CreatePRRequest) (*github.PullRequest, *github.Response, error) {
+						pr = req.NewPullRequest
+						return nil, nil, nil
+					})
+			}
+
+			// create mock updater client
+			client := NewClient(context.Background(), Dependencies{
+				Client: githubMockClient,
+			})
+
+			err := client.UpdateK3sRelease(context.Background(), UpdateReleaseReq{
+				Repo: Repository{
+					Owner:       "some owner",
+					Name:        "some name",
+					Inventories: []string{c.inventory},
+					Branch:      "main",
+				},
+				ReleaseRepo: Repository{
+					Owner: "k3s-io",
+					Name:  "k3s",
+				},
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(updated, c.expectedUpdates) {
+				t.Fatalf("expected updates %v, got %v", c.expectedUpdates, updated)
+			}
+			body := pr.GetBody()
+			if !strings.Contains(body, "is defined with different versions in `"+c.inventory+"`") {
+				t.Fatalf("expected body to report conflicting definitions, got %q", body)
+			}
+			for _, row := range c.expectedNote {
+				if !strings.Contains(body, row) {
+					t.Fatalf("expected body to contain %q, got %q", row, body)
+				}
+			}
+
+			// Rows are ordered by precedence
+			if strings.Index(body, c.expectedNote[0]) > strings.Index(body, c.expectedNote[2]) {
+				t.Fatalf("expected definitions ordered by precedence, got %q", body)
+			}
+		})
+	}
+}
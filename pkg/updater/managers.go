@@ -0,0 +1,151 @@
+package updater
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manager reads and rewrites the k3s
+// version of a given file format.
+type manager interface {
+	// extract returns the current
+	// version pinned in the file.
+	extract(req UpdateReleaseReq, content []byte) (string, error)
+
+	// update returns the content of the
+	// file pinning the new version.
+	update(req updateFileReq) ([]byte, error)
+
+	// scan returns every value of the
+	// file that looks like a k3s version.
+	scan(filePath string, content []byte) []versionPin
+}
+
+const (
+	// ansibleManager updates a key of
+	// ansible group_vars/host_vars files.
+	ansibleManager string = "ansible"
+
+	// planManager updates system-upgrade-controller
+	// Plan manifests.
+	planManager string = "plan"
+
+	// terraformManager updates terraform
+	// variables and .tfvars assignments.
+	terraformManager string = "terraform"
+
+	// envManager updates KEY=value assignments
+	// of env files, scripts and Dockerfiles.
+	envManager string = "env"
+)
+
+var managers = map[string]manager{
+	ansibleManager:   ansibleVarsManager{},
+	planManager:      upgradePlanManager{},
+	terraformManager: terraformFileManager{},
+	envManager:       envFileManager{},
+}
+
+// splitManager
+//
+// Splits the manager prefix of a configured path,
+// i.e.: "plan:manifests/k3s-plan.yml". The manager
+// is empty when the path has no known prefix.
+func splitManager(p string) (name, filePath string) {
+	if prefix, rest, found := strings.Cut(p, ":"); found {
+		if _, ok := managers[prefix]; ok {
+			return prefix, rest
+		}
+	}
+	return "", p
+}
+
+// managerNames
+//
+// Returns the names of the known
+// managers, in alphabetical order.
+func managerNames() []string {
+	names := make([]string, 0, len(managers))
+	for name := range managers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// detectManager
+//
+// Returns the name of the manager handling a
+// file, from its name and content, when no
+// manager was configured for it.
+func detectManager(filePath string, content []byte) (string, error) {
+	if isEnvFile(filePath) {
+		return envManager, nil
+	}
+
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".yml", ".yaml", "":
+		if isUpgradePlan(content) {
+			return planManager, nil
+		}
+		return ansibleManager, nil
+	case ".tf", ".tfvars", ".tofu":
+		return terraformManager, nil
+	default:
+		return "", fmt.Errorf("error when detecting the type of %q: unsupported file, use one of the %s prefixes", filePath, strings.Join(managerNames(), ", "))
+	}
+}
+
+// ansibleVarsManager updates the version
+// key of ansible variable files.
+type ansibleVarsManager struct{}
+
+func (ansibleVarsManager) extract(req UpdateReleaseReq, content []byte) (string, error) {
+	keyPath, err := parseKeyPath(req.versionKey())
+	if err != nil {
+		return "", err
+	}
+
+	scalar, err := findYAMLScalar(content, keyPath)
+	if err != nil {
+		return "", err
+	}
+
+	return scalar.node.Value, nil
+}
+
+func (ansibleVarsManager) update(req updateFileReq) ([]byte, error) {
+	keyPath, err := parseKeyPath(req.versionKey())
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := setYAMLScalar([]byte(req.fileContent), keyPath, *req.latestRelease.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateChecksum(req, content)
+}
+
+func (ansibleVarsManager) scan(_ string, content []byte) []versionPin {
+	docs, err := yamlDocuments(content)
+	if err != nil {
+		return nil
+	}
+
+	pins := make([]versionPin, 0)
+	for _, doc := range docs {
+		walkYAMLScalars(doc, "", func(keyPath string, node *yaml.Node) {
+			if looksLikeK3sVersion(keyPath, node.Value) {
+				pins = append(pins, versionPin{key: keyPath, version: node.Value})
+			}
+		})
+	}
+
+	return pins
+}
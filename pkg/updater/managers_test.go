@@ -0,0 +1,119 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"testing"
+)
+
+func TestSplitManager(t *testing.T) {
+	cases := map[string]struct {
+		path string
+
+		expectedName string
+		expectedPath string
+	}{
+		"path with no prefix": {
+			path:         "inventory/prod/group_vars/all.yml",
+			expectedPath: "inventory/prod/group_vars/all.yml",
+		},
+		"path with a known prefix": {
+			path:         "plan:manifests/k3s-upgrade.yml",
+			expectedName: planManager,
+			expectedPath: "manifests/k3s-upgrade.yml",
+		},
+		"path with the env prefix": {
+			path:         "env:cloud-init/user-data.yml",
+			expectedName: envManager,
+			expectedPath: "cloud-init/user-data.yml",
+		},
+		"path with an unknown prefix": {
+			path:         "other:manifests/k3s-upgrade.yml",
+			expectedPath: "other:manifests/k3s-upgrade.yml",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			managerName, path := splitManager(c.path)
+			if managerName != c.expectedName || path != c.expectedPath {
+				t.Fatalf("expected %q and %q, got %q and %q", c.expectedName, c.expectedPath, managerName, path)
+			}
+		})
+	}
+}
+
+func TestDetectManager(t *testing.T) {
+	cases := map[string]struct {
+		path    string
+		content string
+
+		expected    string
+		expectError bool
+	}{
+		"ansible variables": {
+			path:     "inventory/prod/group_vars/all.yml",
+			content:  "k3s_release_version: v1.28.5+k3s1",
+			expected: ansibleManager,
+		},
+		"upgrade plan": {
+			path:     "manifests/k3s-upgrade.yaml",
+			content:  "apiVersion: upgrade.cattle.io/v1\nkind: Plan\nspec:\n  version: v1.28.5+k3s1\n",
+			expected: planManager,
+		},
+		"ansible variables with no extension": {
+			path:     "inventory/prod/group_vars/all",
+			content:  "k3s_release_version: v1.28.5+k3s1",
+			expected: ansibleManager,
+		},
+		"terraform variables": {
+			path:     "modules/k3s/variables.tf",
+			content:  `variable "k3s_version" { default = "v1.28.5+k3s1" }`,
+			expected: terraformManager,
+		},
+		"terraform variable values": {
+			path:     "environments/prod.tfvars",
+			content:  `k3s_version = "v1.28.5+k3s1"`,
+			expected: terraformManager,
+		},
+		"env file": {
+			path:     "cluster/k3s.env",
+			content:  "INSTALL_K3S_VERSION=v1.28.5+k3s1",
+			expected: envManager,
+		},
+		"dockerfile": {
+			path:     "images/k3s/Dockerfile",
+			content:  "ARG K3S_VERSION=v1.28.5+k3s1",
+			expected: envManager,
+		},
+		"shell script": {
+			path:     "scripts/install.sh",
+			content:  "INSTALL_K3S_VERSION=v1.28.5+k3s1 sh -",
+			expected: envManager,
+		},
+		"unsupported file": {
+			path:        "k3s.json",
+			content:     "{}",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			managerName, err := detectManager(c.path, []byte(c.content))
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if managerName != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, managerName)
+			}
+		})
+	}
+}
@@ -2,13 +2,13 @@ package updater
 
 import (
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
-	"regexp"
+	"slices"
 	"strings"
 	"time"
 
-	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	"github.com/cguertin14/k3supdater/pkg/platform"
 	"github.com/cguertin14/logger"
 	"github.com/google/go-github/v57/github"
 	"golang.org/x/mod/semver"
@@ -19,16 +19,140 @@ type Repository struct {
 	Name   string
 	Path   string
 	Branch string
+
+	// Paths are other file paths or glob patterns
+	// (i.e.: "inventory/*/group_vars/all.yml") to
+	// update along with Path, in the same PR.
+	Paths []string
+
+	// Inventories are ansible inventory directories
+	// (i.e.: "inventory/prod") in which every group_vars
+	// and host_vars definition of the version key is
+	// updated to the same version.
+	Inventories []string
+}
+
+// platform
+//
+// Returns the repository on its platform.
+func (r Repository) platform() platform.Repository {
+	return platform.Repository{Owner: r.Owner, Name: r.Name}
 }
 
 type UpdateReleaseReq struct {
 	Repo        Repository
 	ReleaseRepo Repository
+
+	// ReleaseSource is where versions are found on
+	// the release repository. Defaults to "releases".
+	ReleaseSource ReleaseSource
+
+	// Channel, when set, resolves the target version
+	// from the k3s channel server (i.e.: stable, latest,
+	// v1.30) instead of the github releases.
+	Channel string
+
+	// ChannelURL is the location of the channel document.
+	// It can either be an http(s) URL or a local file.
+	// Defaults to DefaultChannelURL.
+	ChannelURL string
+
+	// AllowUnverifiedChannelRelease proposes the version
+	// of the channel when its github release is missing,
+	// even though its assets and age can't be verified.
+	AllowUnverifiedChannelRelease bool
+
+	// UpdatePolicy limits how far from the current
+	// version an update can go. Defaults to "major".
+	UpdatePolicy UpdatePolicy
+
+	// VersionConstraint is an optional semver range
+	// (i.e.: ">=v1.29.0 <v1.31.0") that proposed
+	// versions must satisfy.
+	VersionConstraint string
+
+	// Stepwise only proposes the next minor version
+	// when the current one is more than one minor
+	// behind, since Kubernetes can't skip minors.
+	Stepwise bool
+
+	// MinReleaseAge is how long a release must have
+	// been published before it can be proposed.
+	MinReleaseAge time.Duration
+
+	// AllowPrereleases allows release candidates and
+	// other pre-releases to be proposed as updates.
+	AllowPrereleases bool
+
+	// DeniedVersions are versions or semver ranges
+	// (i.e.: "v1.30.2", ">=v1.30.0 <v1.30.3") that
+	// must never be proposed.
+	DeniedVersions []string
+
+	// PinnedVersion, when set, is the highest
+	// version that can be proposed.
+	PinnedVersion string
+
+	// Architectures are the architectures of the
+	// cluster nodes (i.e.: amd64, arm64, arm). Their
+	// k3s binary and checksums file must be attached
+	// to a release for it to be proposed.
+	Architectures []string
+
+	// RequiredAssets, when set, replaces the assets
+	// derived from the architectures with a list of
+	// asset name patterns (i.e.: "k3s-airgap-*").
+	RequiredAssets []string
+
+	// VerifyChecksums downloads the k3s binary of every
+	// architecture and verifies it against the checksums
+	// file of the release before proposing it.
+	VerifyChecksums bool
+
+	// ChecksumKey, when set along with VerifyChecksums,
+	// is the key of the group_vars file updated with the
+	// verified checksum of the first architecture.
+	ChecksumKey string
+
+	// VersionKey is the path of the version in the
+	// yaml document, either dotted (i.e.: k3s.version)
+	// or JSONPath-like (i.e.: $.k3s['version']).
+	// Defaults to DefaultVersionKey.
+	VersionKey string
+
+	// VaultPassword decrypts the files encrypted
+	// with ansible-vault, which are encrypted again
+	// once updated.
+	VaultPassword string
+
+	// Reviewers are the users requested to review
+	// the pull request, identified as expected by
+	// the platform hosting the repository.
+	Reviewers []string
+}
+
+// versionKey
+//
+// Returns the configured version
+// key, or the default one.
+func (req UpdateReleaseReq) versionKey() string {
+	if req.VersionKey == "" {
+		return DefaultVersionKey
+	}
+	return req.VersionKey
 }
 
 type getLatestK3sReleaseRequest struct {
 	UpdateReleaseReq
 	fileContent string
+
+	// manager reads the version of the file,
+	// ansible variables being the default.
+	manager manager
+
+	// releases are the candidate releases, which
+	// are fetched when not provided.
+	releases []*github.RepositoryRelease
 }
 
 type createNewBranchReq struct {
@@ -37,11 +161,16 @@ type createNewBranchReq struct {
 }
 
 type updateFileReq struct {
-	fileContent    string
-	currentVersion string
-	latestRelease  *github.RepositoryRelease
-	repoContent    *github.RepositoryContent
-	branchName     string
+	fileContent   string
+	latestRelease *github.RepositoryRelease
+	checksums     []assetChecksum
+	manager       manager
+	UpdateReleaseReq
+}
+
+type commitFilesReq struct {
+	branchName string
+	files      []*versionFile
 	UpdateReleaseReq
 }
 
@@ -49,184 +178,178 @@ type createPRRequest struct {
 	currentVersion string
 	latestRelease  *github.RepositoryRelease
 	branchName     string
+	notes          []string
+	files          []*versionFile
 	UpdateReleaseReq
 }
 
 const (
-	k3sVersionKey string = "k3s_release_version"
+	// DefaultVersionKey is the group_vars key
+	// holding the version in k3s-ansible playbooks.
+	DefaultVersionKey string = "k3s_release_version"
 )
 
-func (c *ClientSet) getGroupVarsFileContent(ctx context.Context, req UpdateReleaseReq) (repoContent *github.RepositoryContent, fileContent string, err error) {
+func (c *ClientSet) getGroupVarsFileContent(ctx context.Context, req UpdateReleaseReq) (fileContent string, err error) {
 	logger := logger.NewFromContextOrDefault(ctx)
 	logger.Infof("Fetching %q from %s/%s...", req.Repo.Path, req.Repo.Owner, req.Repo.Name)
 
-	repoContent, _, _, err = c.client.GetRepositoryContents(ctx, legacy.GetRepositoryContentsRequest{
-		Owner:  req.Repo.Owner,
-		Repo:   req.Repo.Name,
-		Path:   req.Repo.Path,
-		Branch: req.Repo.Branch,
+	content, err := c.platform.GetFile(ctx, platform.GetFileRequest{
+		Repository: req.Repo.platform(),
+		Path:       req.Repo.Path,
+		Branch:     req.Repo.Branch,
 	})
 	if err != nil {
-		err = fmt.Errorf("error when fetching repo: %s", err)
+		err = fmt.Errorf("error when fetching %q: %s", req.Repo.Path, err)
 		return
 	}
 
-	decoded, err := base64.StdEncoding.DecodeString(*repoContent.Content)
-	if err != nil {
-		err = fmt.Errorf("error when decoding %q: %s", req.Repo.Path, err)
-		return
-	}
-
-	fileContent = string(decoded)
+	fileContent = string(content)
 	return
 }
 
-func (c *ClientSet) getLatestK3sRelease(ctx context.Context, req getLatestK3sReleaseRequest) (latestRelease *github.RepositoryRelease, currentVersion string, err error) {
+func (c *ClientSet) getLatestK3sRelease(ctx context.Context, req getLatestK3sReleaseRequest) (latestRelease *github.RepositoryRelease, currentVersion string, notes []string, err error) {
 	logger := logger.NewFromContextOrDefault(ctx)
-	logger.Infof("Fetching the latest k3s release from %s/%s...", req.ReleaseRepo.Owner, req.ReleaseRepo.Name)
 
-	regz := regexp.MustCompile(fmt.Sprintf("%s.*", k3sVersionKey))
-	extracted := regz.FindStringSubmatch(req.fileContent)
-	if len(extracted) == 0 {
-		return nil, "", fmt.Errorf("error when extracting k3s version: %q key not found in %q", k3sVersionKey, req.Repo.Path)
+	m := req.manager
+	if m == nil {
+		m = managers[ansibleManager]
 	}
 
-	currentVersion = extracted[0][len(k3sVersionKey)+2:]
-	releases, _, err := c.client.GetRepositoryReleases(ctx, legacy.CommonRequest{
-		Owner: req.ReleaseRepo.Owner,
-		Repo:  req.ReleaseRepo.Name,
-	})
+	currentVersion, err = m.extract(req.UpdateReleaseReq, []byte(req.fileContent))
 	if err != nil {
-		return nil, "", fmt.Errorf(
-			"error when fetching releases from %s/%s: %s",
-			req.ReleaseRepo.Owner,
-			req.ReleaseRepo.Name,
-			err,
-		)
-	}
-
-	// Find latest stable versions among all releases
-	latestStableVersions := make([]*github.RepositoryRelease, 0)
-	for _, r := range releases {
-		// Exclude release candidates since
-		// they're not stable versions
-		if strings.Contains(*r.Name, "rc") {
-			continue
-		}
+		return nil, "", nil, fmt.Errorf("error when extracting k3s version from %q: %s", req.Repo.Path, err)
+	}
 
-		// Exclude pre-releases, since they're
-		// not fully ready yet
-		if *r.Prerelease {
-			logger.Warnf("A new pre-release is available: %q\n", *r.Name)
-			continue
+	releases := req.releases
+	if releases == nil {
+		if releases, err = c.getCandidateReleases(ctx, req.UpdateReleaseReq); err != nil {
+			return nil, "", nil, err
 		}
+	}
 
-		// Keep only three releases.
-		if len(latestStableVersions) < 3 {
-			latestStableVersions = append(latestStableVersions, r)
-		}
+	eligible, skipped, err := eligibleReleases(ctx, req.UpdateReleaseReq, currentVersion, releases)
+	if err != nil {
+		return nil, "", nil, err
 	}
 
 	latestRelease = &github.RepositoryRelease{}
-	for _, v := range latestStableVersions {
-		compared := semver.Compare(currentVersion, *v.Name)
-		if compared == -1 {
-			// if compared == -1, this means we need to
-			// update to that specific version.
-			//
-			// if compared == 0, this means we're at
-			// the latest version available.
-			//
-			// if compared == 1, this means the current
-			// version is more recent than the other ones.
-			latestRelease = v
-			logger.Warnf("A new k3s version is available: %q", *latestRelease.Name)
-
-			// FUTURE TODO: Send a notification via Slack, Email, Discord, whatever, to inform
-			// user about the new version available.
-
-			break
+	if len(eligible) > 0 {
+		latestRelease = eligible[0]
+		if req.Stepwise {
+			var remaining []*github.RepositoryRelease
+			latestRelease, remaining = nextMinorRelease(currentVersion, eligible)
+			if latestRelease == nil {
+				logger.Warnf("No eligible release of k3s %s, the next minor version after %q, therefore not proposing %q which would skip it.", nextMinor(currentVersion), currentVersion, *eligible[0].Name)
+				return &github.RepositoryRelease{}, currentVersion, nil, nil
+			}
+			if latestRelease != eligible[0] && semver.MajorMinor(*latestRelease.Name) == semver.MajorMinor(currentVersion) {
+				logger.Warnf("No eligible release of k3s %s, the next minor version after %q, therefore proposing the %q patch release rather than %q which would skip it.", nextMinor(currentVersion), currentVersion, *latestRelease.Name, *eligible[0].Name)
+			}
+			if len(remaining) > 0 {
+				logger.Infof("Stepping through minor versions, proposing %q first.", *latestRelease.Name)
+				notes = append(notes, stepwiseNote(remaining))
+			}
+		}
+		logger.Warnf("A new k3s version is available: %q", *latestRelease.Name)
+
+		if note := skippedNote(*latestRelease.Name, skipped); note != "" {
+			notes = append(notes, note)
 		}
+
+		// FUTURE TODO: Send a notification via Slack, Email, Discord, whatever, to inform
+		// user about the new version available.
 	}
 
 	return
 }
 
 func (c *ClientSet) createNewBranch(ctx context.Context, req createNewBranchReq) (branchName string, err error) {
-	branch, _, err := c.client.GetBranch(ctx, legacy.GetBranchRequest{
-		Owner:      req.Repo.Owner,
-		Repo:       req.Repo.Name,
-		BranchName: fmt.Sprintf("refs/heads/%s", req.Repo.Branch),
+	branchName = fmt.Sprintf("release/k3s-%s-update", *req.latestRelease.Name)
+	err = c.platform.CreateBranch(ctx, platform.CreateBranchRequest{
+		Repository: req.Repo.platform(),
+		Base:       req.Repo.Branch,
+		Branch:     branchName,
 	})
 	if err != nil {
-		return "", fmt.Errorf("error when fetching branch %q: %s", req.Repo.Branch, err)
+		return "", fmt.Errorf("error when creating branch %q: %w", branchName, err)
 	}
 
-	branchName = fmt.Sprintf("release/k3s-%s-update", *req.latestRelease.Name)
-	_, _, err = c.client.CreateBranch(ctx, legacy.CreateBranchRequest{
-		Owner: req.Repo.Owner,
-		Repo:  req.Repo.Name,
-		Reference: &github.Reference{
-			Ref:    github.String(fmt.Sprintf("refs/heads/%s", branchName)),
-			Object: branch.Object,
-		},
-	})
+	return
+}
+
+// updateFile
+//
+// Returns the content of a file
+// updated to the new version.
+func updateFile(req updateFileReq) ([]byte, error) {
+	m := req.manager
+	if m == nil {
+		m = managers[ansibleManager]
+	}
+
+	content, err := m.update(req)
 	if err != nil {
-		return "", fmt.Errorf("error when creating branch %q: %s", branchName, err)
+		return nil, fmt.Errorf("error when updating file %q: %s", req.Repo.Path, err)
 	}
 
-	return
+	return content, nil
 }
 
-func (c *ClientSet) updateFile(ctx context.Context, req updateFileReq) (err error) {
-	now := time.Now()
-	newGroupVarsFileContent := strings.ReplaceAll(
-		req.fileContent,
-		fmt.Sprintf("%s: %s", k3sVersionKey, req.currentVersion),
-		fmt.Sprintf("%s: %s", k3sVersionKey, *req.latestRelease.Name),
-	)
+// commitFiles
+//
+// Commits the updated files to the new branch, all at
+// once, through the git data API: a blob is created for
+// each file, then a tree and a commit holding all of
+// them. The branch only moves to the new commit once
+// every object exists, so a failure leaves it untouched.
+func (c *ClientSet) commitFiles(ctx context.Context, req commitFilesReq) error {
+	files := make([]platform.File, 0, len(req.files))
+	for _, f := range req.files {
+		files = append(files, platform.File{Path: f.path, Content: f.updatedContent})
+	}
 
-	_, _, err = c.client.UpdateFile(ctx, legacy.UpdateFileRequest{
-		Owner:    req.Repo.Owner,
-		Repo:     req.Repo.Name,
-		FilePath: req.Repo.Path,
-		RepositoryContentFileOptions: &github.RepositoryContentFileOptions{
-			Content: []byte(newGroupVarsFileContent),
-			Branch:  github.String(req.branchName),
-			Committer: &github.CommitAuthor{
-				Name:  github.String("k3supdater-bot"),
-				Email: github.String("k3supdater-bot@k3s.io"),
-				Date:  &github.Timestamp{Time: now},
-			},
-			Message: github.String(
-				fmt.Sprintf("Updated k3s version %s to %s.", req.currentVersion, *req.latestRelease.Name),
-			),
-			SHA: req.repoContent.SHA,
+	err := c.platform.Commit(ctx, platform.CommitRequest{
+		Repository: req.Repo.platform(),
+		Branch:     req.branchName,
+		Message:    commitMessage(req.files),
+		Author: platform.Author{
+			Name:  "k3supdater-bot",
+			Email: "k3supdater-bot@k3s.io",
 		},
+		Files: files,
 	})
 	if err != nil {
-		return fmt.Errorf("error when updating file %q: %s", req.Repo.Path, err)
+		return fmt.Errorf("error when committing files: %s", err)
 	}
 
-	return
+	return nil
+}
+
+// commitMessage
+//
+// Returns the message of the commit
+// updating the given files.
+func commitMessage(files []*versionFile) string {
+	if len(files) == 1 {
+		return fmt.Sprintf("Updated k3s version %s to %s.", files[0].currentVersion, *files[0].latestRelease.Name)
+	}
+
+	lines := []string{fmt.Sprintf("Updated k3s version in %d files.", len(files)), ""}
+	for _, f := range files {
+		lines = append(lines, fmt.Sprintf("- %s: %s to %s.", f.path, f.currentVersion, *f.latestRelease.Name))
+	}
+
+	return strings.Join(lines, "\n")
 }
 
 func (c *ClientSet) createPR(ctx context.Context, req createPRRequest) error {
-	_, _, err := c.client.CreatePullRequest(ctx, legacy.CreatePRRequest{
-		Owner: req.Repo.Owner,
-		Repo:  req.Repo.Name,
-		NewPullRequest: &github.NewPullRequest{
-			Base: github.String(req.Repo.Branch),
-			Head: github.String(req.branchName),
-			Body: req.latestRelease.Body,
-			Title: github.String(
-				fmt.Sprintf(
-					"new release: k3s update from %s to %s",
-					req.currentVersion,
-					*req.latestRelease.Name,
-				),
-			),
-		},
+	err := c.platform.CreatePullRequest(ctx, platform.CreatePullRequestRequest{
+		Repository: req.Repo.platform(),
+		Base:       req.Repo.Branch,
+		Head:       req.branchName,
+		Body:       pullRequestBody(req),
+		Title:      pullRequestTitle(req),
+		Reviewers:  req.Reviewers,
 	})
 	if err != nil {
 		return fmt.Errorf("error when opening pull request on repository: %s", err)
@@ -237,51 +360,195 @@ func (c *ClientSet) createPR(ctx context.Context, req createPRRequest) error {
 
 func (c *ClientSet) UpdateK3sRelease(ctx context.Context, req UpdateReleaseReq) (err error) {
 	logger := logger.NewFromContextOrDefault(ctx)
-	repoContent, fileContent, err := c.getGroupVarsFileContent(ctx, req)
+	paths, err := c.resolvePaths(ctx, req)
 	if err != nil {
 		return
 	}
 
-	latestRelease, currentVersion, err := c.getLatestK3sRelease(ctx, getLatestK3sReleaseRequest{
-		UpdateReleaseReq: req,
-		fileContent:      fileContent,
-	})
+	cache := make(inventoryCache)
+	files := make([]*versionFile, 0, len(paths))
+	for _, p := range paths {
+		name, path := splitManager(p)
+		f, err := c.readVersionFile(ctx, req.forPath(path))
+		if err != nil {
+			return err
+		}
+
+		if name == "" {
+			if name, err = detectManager(path, []byte(f.content)); err != nil {
+				return err
+			}
+		}
+
+		f.manager = managers[name]
+		if name == ansibleManager {
+			if err = c.resolveTemplate(ctx, req.forPath(path), f, cache); err != nil {
+				return err
+			}
+		}
+		files = append(files, f)
+	}
+
+	// Releases are fetched once, and every
+	// file gets its own target version.
+	logger.Infof("Fetching the latest k3s release from %s/%s...", req.ReleaseRepo.Owner, req.ReleaseRepo.Name)
+	releases, err := c.getCandidateReleases(ctx, req)
 	if err != nil {
 		return
 	}
 
-	// No update required in this case
-	if latestRelease.Name == nil {
-		logger.Infof("Current version %q is the latest version available for k3s, therefore not updating.", currentVersion)
+	notes := make([]string, 0)
+	updates := make([]*versionFile, 0, len(files))
+	for _, f := range files {
+		var fileNotes []string
+		f.latestRelease, f.currentVersion, fileNotes, err = c.getLatestK3sRelease(ctx, getLatestK3sReleaseRequest{
+			UpdateReleaseReq: req.forPath(f.path),
+			fileContent:      f.content,
+			manager:          f.manager,
+			releases:         releases,
+		})
+		if err != nil {
+			return
+		}
+
+		// No update required in this case
+		if f.latestRelease.Name == nil {
+			logger.Infof("Current version %q of %q is the latest version available for k3s, therefore not updating it.", f.currentVersion, f.path)
+			continue
+		}
+
+		updates = append(updates, f)
+		for _, note := range fileNotes {
+			if !slices.Contains(notes, note) {
+				notes = append(notes, note)
+			}
+		}
+	}
+
+	// Every definition of an inventory gets the
+	// same target, unless already listed above.
+	for _, inventory := range req.Repo.Inventories {
+		definitions, err := c.inventoryDefinitions(ctx, req, inventory, cache)
+		if err != nil {
+			return err
+		}
+		definitions = slices.DeleteFunc(definitions, func(d *versionFile) bool {
+			return slices.ContainsFunc(files, func(f *versionFile) bool { return f.path == d.path })
+		})
+
+		inventoryNotes, err := c.getInventoryRelease(ctx, req, inventory, definitions, releases)
+		if err != nil {
+			return err
+		}
+
+		updated := false
+		for _, d := range definitions {
+			files = append(files, d)
+			if d.latestRelease.Name == nil {
+				logger.Infof("Current version %q of %q is already up to date, therefore not updating it.", d.currentVersion, d.path)
+				continue
+			}
+			updated = true
+			updates = append(updates, d)
+		}
+		if !updated {
+			continue
+		}
+		for _, note := range inventoryNotes {
+			if !slices.Contains(notes, note) {
+				notes = append(notes, note)
+			}
+		}
+	}
+	if len(updates) == 0 {
 		return nil
 	}
 
+	// The newest target version names
+	// the branch and the pull request.
+	latestRelease := updates[0].latestRelease
+	for _, f := range updates[1:] {
+		if compareK3sVersions(*f.latestRelease.Name, *latestRelease.Name) > 0 {
+			latestRelease = f.latestRelease
+		}
+	}
+
+	// Make sure the binaries of the new versions
+	// match their published checksums before
+	// proposing them.
+	checksums := make(map[string][]assetChecksum)
+	if req.VerifyChecksums {
+		for _, f := range updates {
+			if _, ok := checksums[*f.latestRelease.Name]; ok {
+				continue
+			}
+			verified, err := c.verifyChecksums(ctx, req, f.latestRelease)
+			if err != nil {
+				return err
+			}
+			checksums[*f.latestRelease.Name] = verified
+			notes = append(notes, checksumsNote(verified))
+		}
+
+		// The checksum is only written where its key is
+		// defined, which must be in one file at least.
+		if req.ChecksumKey != "" && !slices.ContainsFunc(updates, func(f *versionFile) bool {
+			return definesKey(f.content, req.ChecksumKey)
+		}) {
+			return fmt.Errorf("error when updating checksum: %q key not found in any updated file", req.ChecksumKey)
+		}
+	}
+
 	// Proceed to make the update
 	//
-	// Step 1: Create a new branch
-	// Step 2: Update file content locally
-	// Step 3: Update file content on github repo, on a new branch
+	// Step 1: Update files content locally
+	// Step 2: Create a new branch
+	// Step 3: Commit every file at once on the new branch
 	// Step 4: Open pull request with new release details.
+	for _, f := range updates {
+		if f.updatedContent, err = updateFile(updateFileReq{
+			UpdateReleaseReq: req.forPath(f.path),
+			fileContent:      f.content,
+			latestRelease:    f.latestRelease,
+			checksums:        checksums[*f.latestRelease.Name],
+			manager:          f.manager,
+		}); err != nil {
+			return
+		}
+	}
+
+	// Variables of templated versions may
+	// be defined in other files as well.
+	commits, templateNotes, err := templateFiles(updates)
+	if err != nil {
+		return
+	}
+	notes = append(notes, templateNotes...)
+	for _, f := range commits {
+		if f.vault != nil {
+			if f.updatedContent, err = encryptVault(f.vault, f.updatedContent, []byte(req.VaultPassword)); err != nil {
+				return fmt.Errorf("error when encrypting %q: %s", f.path, err)
+			}
+		}
+	}
+
 	branchName, err := c.createNewBranch(ctx, createNewBranchReq{
 		UpdateReleaseReq: req,
 		latestRelease:    latestRelease,
 	})
 	if err != nil {
-		if strings.Contains(err.Error(), "exists") {
+		if errors.Is(err, platform.ErrBranchExists) {
 			logger.Warnln("PR already exists, exiting.")
 			return nil
 		}
 		return
 	}
 
-	// Section 6: update file's content
-	if err = c.updateFile(ctx, updateFileReq{
+	// Section 6: commit files content
+	if err = c.commitFiles(ctx, commitFilesReq{
 		UpdateReleaseReq: req,
-		fileContent:      fileContent,
-		currentVersion:   currentVersion,
-		latestRelease:    latestRelease,
-		repoContent:      repoContent,
 		branchName:       branchName,
+		files:            commits,
 	}); err != nil {
 		return
 	}
@@ -289,12 +556,60 @@ func (c *ClientSet) UpdateK3sRelease(ctx context.Context, req UpdateReleaseReq)
 	// Section 7: create PR
 	if err = c.createPR(ctx, createPRRequest{
 		UpdateReleaseReq: req,
-		currentVersion:   currentVersion,
+		currentVersion:   updates[0].currentVersion,
 		latestRelease:    latestRelease,
 		branchName:       branchName,
+		notes:            notes,
+		files:            files,
 	}); err != nil {
 		return
 	}
 
 	return nil
 }
+
+// pullRequestTitle
+//
+// Returns the title of the pull request, listing
+// every version updated and every new version.
+func pullRequestTitle(req createPRRequest) string {
+	currentVersions := []string{req.currentVersion}
+	newVersions := []string{*req.latestRelease.Name}
+	for _, f := range req.files {
+		if f.latestRelease.GetName() == "" {
+			continue
+		}
+		if !slices.Contains(currentVersions, f.currentVersion) {
+			currentVersions = append(currentVersions, f.currentVersion)
+		}
+		if !slices.Contains(newVersions, *f.latestRelease.Name) {
+			newVersions = append(newVersions, *f.latestRelease.Name)
+		}
+	}
+
+	return fmt.Sprintf(
+		"new release: k3s update from %s to %s",
+		strings.Join(currentVersions, ", "),
+		strings.Join(newVersions, ", "),
+	)
+}
+
+// pullRequestBody
+//
+// Returns the release notes of the new version,
+// preceded by the summary of the updated files, if
+// there are several, and the notes gathered while
+// picking the new version.
+func pullRequestBody(req createPRRequest) string {
+	notes := req.notes
+	if len(req.files) > 1 {
+		notes = append([]string{filesNote(req.files)}, notes...)
+	}
+
+	body := req.latestRelease.GetBody()
+	if len(notes) == 0 {
+		return body
+	}
+
+	return fmt.Sprintf("%s\n\n---\n\n%s", strings.Join(notes, "\n\n"), body)
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"testing"
 
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
 	github_mocks "github.com/cguertin14/k3supdater/pkg/github/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-github/v57/github"
@@ -26,7 +27,7 @@ func TestUpdateK3sRelease(t *testing.T) {
 		groupVarsFileContentError error
 		latestReleaseError        error
 		createNewBranchError      error
-		updateFileError           error
+		commitFilesError          error
 		createPRError             error
 
 		expectError bool
@@ -47,9 +48,9 @@ func TestUpdateK3sRelease(t *testing.T) {
 			createNewBranchError: errors.New("some error"),
 			expectError:          true,
 		},
-		"error case with update file error": {
-			updateFileError: errors.New("some error"),
-			expectError:     true,
+		"error case with commit files error": {
+			commitFilesError: errors.New("some error"),
+			expectError:      true,
 		},
 		"error case with create PR error": {
 			createPRError: errors.New("some error"),
@@ -75,7 +76,7 @@ func TestUpdateK3sRelease(t *testing.T) {
 				Return(&github.RepositoryContent{
 					SHA: github.String("some sha"),
 					Content: github.String(
-						base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s: %s", k3sVersionKey, c.currentVersion))),
+						base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s: %s", DefaultVersionKey, c.currentVersion))),
 					),
 				}, nil, nil, c.groupVarsFileContentError)
 			githubMockClient.EXPECT().GetRepositoryReleases(gomock.Any(), gomock.Any()).
@@ -103,16 +104,32 @@ func TestUpdateK3sRelease(t *testing.T) {
 					},
 				}, nil, c.latestReleaseError)
 			githubMockClient.EXPECT().GetBranch(gomock.Any(), gomock.Any()).
-				MaxTimes(1).
+				MaxTimes(2).
 				Return(&github.Reference{
 					Object: &github.GitObject{},
 				}, nil, nil)
 			githubMockClient.EXPECT().CreateBranch(gomock.Any(), gomock.Any()).
 				MaxTimes(1).
 				Return(nil, nil, c.createNewBranchError)
-			githubMockClient.EXPECT().UpdateFile(gomock.Any(), gomock.Any()).
+			githubMockClient.EXPECT().GetCommit(gomock.Any(), gomock.Any()).
+				MaxTimes(1).
+				Return(&github.Commit{}, nil, nil)
+			// the base tree, for the modes of the committed files
+			githubMockClient.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+				MaxTimes(1).
+				Return(&github.Tree{}, nil, nil)
+			githubMockClient.EXPECT().CreateBlob(gomock.Any(), gomock.Any()).
+				MaxTimes(1).
+				Return(&github.Blob{}, nil, nil)
+			githubMockClient.EXPECT().CreateTree(gomock.Any(), gomock.Any()).
 				MaxTimes(1).
-				Return(nil, nil, c.updateFileError)
+				Return(&github.Tree{}, nil, nil)
+			githubMockClient.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+				MaxTimes(1).
+				Return(&github.Commit{}, nil, c.commitFilesError)
+			githubMockClient.EXPECT().UpdateRef(gomock.Any(), gomock.Any()).
+				MaxTimes(1).
+				Return(nil, nil, nil)
 			githubMockClient.EXPECT().CreatePullRequest(gomock.Any(), gomock.Any()).
 				MaxTimes(1).
 				Return(nil, nil, c.createPRError)
@@ -183,7 +200,7 @@ func TestGetGroupVarsFileContent(t *testing.T) {
 				Client: githubMockClient,
 			})
 
-			_, _, err := client.getGroupVarsFileContent(context.Background(), UpdateReleaseReq{
+			_, err := client.getGroupVarsFileContent(context.Background(), UpdateReleaseReq{
 				Repo: Repository{
 					Owner:  "some owner",
 					Name:   "some name",
@@ -234,10 +251,10 @@ func TestGetLatestK3sRelease(t *testing.T) {
 		expectError   bool
 	}{
 		"success case with no error": {
-			fileContent: fmt.Sprintf("%s: %s", k3sVersionKey, "v1.23.3"),
+			fileContent: fmt.Sprintf("%s: %s", DefaultVersionKey, "v1.23.3"),
 		},
 		"success case with a pre-release": {
-			fileContent: fmt.Sprintf("%s: %s", k3sVersionKey, "v1.23.3"),
+			fileContent: fmt.Sprintf("%s: %s", DefaultVersionKey, "v1.23.3"),
 			preRelease:  true,
 		},
 		"error case with response error": {
@@ -272,7 +289,7 @@ func TestGetLatestK3sRelease(t *testing.T) {
 				Client: githubMockClient,
 			})
 
-			_, _, err := client.getLatestK3sRelease(context.Background(), getLatestK3sReleaseRequest{
+			_, _, _, err := client.getLatestK3sRelease(context.Background(), getLatestK3sReleaseRequest{
 				UpdateReleaseReq: UpdateReleaseReq{
 					Repo: Repository{
 						Owner:  "some owner",
@@ -294,6 +311,197 @@ func TestGetLatestK3sRelease(t *testing.T) {
 	}
 }
 
+func TestGetLatestK3sReleaseStepwise(t *testing.T) {
+	cases := map[string]struct {
+		releases       []*github.RepositoryRelease
+		deniedVersions []string
+
+		expectedVersion string
+		expectedNotes   int
+	}{
+		"next minor proposed first": {
+			releases: []*github.RepositoryRelease{
+				newTestRelease("v1.30.2+k3s1"),
+				newTestRelease("v1.29.6+k3s1"),
+			},
+			expectedVersion: "v1.29.6+k3s1",
+			expectedNotes:   1,
+		},
+		"patch proposed without the next minor": {
+			releases: []*github.RepositoryRelease{
+				newTestRelease("v1.30.2+k3s1"),
+				newTestRelease("v1.29.6+k3s1"),
+				newTestRelease("v1.28.9+k3s1"),
+			},
+			deniedVersions:  []string{">=v1.29.0 <v1.30.0"},
+			expectedVersion: "v1.28.9+k3s1",
+			expectedNotes:   1,
+		},
+		"nothing proposed without the next minor": {
+			releases: []*github.RepositoryRelease{
+				newTestRelease("v1.30.2+k3s1"),
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			client := NewClient(context.Background(), Dependencies{})
+
+			latestRelease, _, notes, err := client.getLatestK3sRelease(context.Background(), getLatestK3sReleaseRequest{
+				UpdateReleaseReq: UpdateReleaseReq{
+					Repo: Repository{
+						Owner:  "some owner",
+						Name:   "some name",
+						Path:   "/some/existing/path",
+						Branch: "main",
+					},
+					Stepwise:       true,
+					DeniedVersions: c.deniedVersions,
+				},
+				fileContent: fmt.Sprintf("%s: %s", DefaultVersionKey, "v1.28.5+k3s1"),
+				releases:    c.releases,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if latestRelease.GetName() != c.expectedVersion {
+				t.Fatalf("expected %q, got %q", c.expectedVersion, latestRelease.GetName())
+			}
+			if len(notes) != c.expectedNotes {
+				t.Fatalf("expected %d notes, got %v", c.expectedNotes, notes)
+			}
+		})
+	}
+}
+
+func TestGetLatestK3sReleaseOrdering(t *testing.T) {
+	release := func(name string) *github.RepositoryRelease {
+		return &github.RepositoryRelease{
+			Body:       github.String("some release notes"),
+			Name:       github.String(name),
+			Prerelease: github.Bool(false),
+		}
+	}
+
+	cases := map[string]struct {
+		currentVersion string
+		pages          [][]*github.RepositoryRelease
+
+		expectedVersion string
+	}{
+		"out of order releases on a single page": {
+			currentVersion: "v1.28.5+k3s1",
+			pages: [][]*github.RepositoryRelease{
+				{
+					release("v1.28.9+k3s1"),
+					release("v1.27.13+k3s1"),
+					release("v1.30.0-rc1+k3s1"),
+					release("v1.29.4+k3s1"),
+					release("v1.28.10+k3s1"),
+				},
+			},
+			expectedVersion: "v1.29.4+k3s1",
+		},
+		"newest minor only available on a later page": {
+			currentVersion: "v1.28.5+k3s1",
+			pages: [][]*github.RepositoryRelease{
+				{
+					release("v1.28.9+k3s1"),
+					release("v1.27.13+k3s1"),
+				},
+				{
+					release("v1.26.15+k3s1"),
+					release("v1.28.8+k3s1"),
+				},
+				{
+					release("v1.29.4+k3s1"),
+					release("v1.29.3+k3s1"),
+				},
+			},
+			expectedVersion: "v1.29.4+k3s1",
+		},
+		"invalid release names are ignored": {
+			currentVersion: "v1.28.5+k3s1",
+			pages: [][]*github.RepositoryRelease{
+				{
+					release("some release"),
+					release("v1.28.6+k3s1"),
+				},
+			},
+			expectedVersion: "v1.28.6+k3s1",
+		},
+		"respin of the current version": {
+			currentVersion: "v1.30.4+k3s1",
+			pages: [][]*github.RepositoryRelease{
+				{
+					release("v1.30.4+k3s2"),
+					release("v1.30.4+k3s1"),
+					release("v1.30.3+k3s1"),
+				},
+			},
+			expectedVersion: "v1.30.4+k3s2",
+		},
+		"current version is already the latest one": {
+			currentVersion: "v1.29.4+k3s1",
+			pages: [][]*github.RepositoryRelease{
+				{release("v1.28.9+k3s1")},
+				{release("v1.29.4+k3s1")},
+			},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// create new mock client instance
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+
+			// define mock behavior, one call per page
+			for i, page := range c.pages {
+				resp := &github.Response{}
+				if i < len(c.pages)-1 {
+					resp.NextPage = i + 2
+				}
+				requestedPage := 0
+				if i > 0 {
+					requestedPage = i + 1
+				}
+				githubMockClient.EXPECT().GetRepositoryReleases(gomock.Any(), legacy.ListRequest{
+					Owner: "k3s-io",
+					Repo:  "k3s",
+					Page:  requestedPage,
+				}).
+					Times(1).
+					Return(page, resp, nil)
+			}
+
+			// create mock updater client
+			client := NewClient(context.Background(), Dependencies{
+				Client: githubMockClient,
+			})
+
+			latestRelease, _, _, err := client.getLatestK3sRelease(context.Background(), getLatestK3sReleaseRequest{
+				UpdateReleaseReq: UpdateReleaseReq{
+					ReleaseRepo: Repository{
+						Owner: "k3s-io",
+						Name:  "k3s",
+					},
+				},
+				fileContent: fmt.Sprintf("%s: %s", DefaultVersionKey, c.currentVersion),
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if latestRelease.GetName() != c.expectedVersion {
+				t.Fatalf("expected version %q, got %q", c.expectedVersion, latestRelease.GetName())
+			}
+		})
+	}
+}
+
 func TestCreateNewBranch(t *testing.T) {
 	cases := map[string]struct {
 		getBranchError    error
@@ -360,14 +568,122 @@ func TestCreateNewBranch(t *testing.T) {
 
 func TestUpdateFile(t *testing.T) {
 	cases := map[string]struct {
-		updateFileError error
+		fileContent string
+		checksumKey string
+		checksums   []assetChecksum
+
+		expectedContent string
 		expectError     bool
+	}{
+		"success case with no error": {
+			fileContent:     fmt.Sprintf("%s: %s", DefaultVersionKey, "v1.23.4"),
+			expectedContent: fmt.Sprintf("%s: %s", DefaultVersionKey, "v1.23.5"),
+		},
+		"success case with checksum": {
+			fileContent: fmt.Sprintf("%s: %s\nk3s_checksum: sha256:abc\n", DefaultVersionKey, "v1.23.4"),
+			checksumKey: "k3s_checksum",
+			checksums: []assetChecksum{
+				{arch: "amd64", asset: "k3s", sha256: "def"},
+			},
+			expectedContent: fmt.Sprintf("%s: %s\nk3s_checksum: sha256:def\n", DefaultVersionKey, "v1.23.5"),
+		},
+		"success case with inline vault value": {
+			fileContent:     fmt.Sprintf("%s: %s\nk3s_token: !vault |\n  $ANSIBLE_VAULT;1.1;AES256\n  6162636465\n", DefaultVersionKey, "v1.23.4"),
+			expectedContent: fmt.Sprintf("%s: %s\nk3s_token: !vault |\n  $ANSIBLE_VAULT;1.1;AES256\n  6162636465\n", DefaultVersionKey, "v1.23.5"),
+		},
+		"error case with missing version key": {
+			fileContent: "some_other_key: v1.23.4",
+			expectError: true,
+		},
+		"error case with inline vault version": {
+			fileContent: fmt.Sprintf("%s: !vault |\n  $ANSIBLE_VAULT;1.1;AES256\n  6162636465\n", DefaultVersionKey),
+			expectError: true,
+		},
+		"success case with missing checksum key": {
+			fileContent: fmt.Sprintf("%s: %s", DefaultVersionKey, "v1.23.4"),
+			checksumKey: "k3s_checksum",
+			checksums: []assetChecksum{
+				{arch: "amd64", asset: "k3s", sha256: "def"},
+			},
+			expectedContent: fmt.Sprintf("%s: %s", DefaultVersionKey, "v1.23.5"),
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			content, err := updateFile(updateFileReq{
+				latestRelease: &github.RepositoryRelease{
+					Name: github.String("v1.23.5"),
+				},
+				fileContent: c.fileContent,
+				checksums:   c.checksums,
+				UpdateReleaseReq: UpdateReleaseReq{
+					Repo: Repository{
+						Owner:  "some owner",
+						Name:   "some name",
+						Path:   "/some/existing/path",
+						Branch: "main",
+					},
+					ChecksumKey: c.checksumKey,
+				},
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(content) != c.expectedContent {
+				t.Fatalf("expected %q, got %q", c.expectedContent, string(content))
+			}
+		})
+	}
+}
+
+func TestCommitFiles(t *testing.T) {
+	cases := map[string]struct {
+		getBranchError    error
+		getCommitError    error
+		getTreeError      error
+		createBlobError   error
+		createTreeError   error
+		createCommitError error
+		updateRefError    error
+
+		expectError bool
 	}{
 		"success case with no error": {},
-		"error case with update file error": {
-			updateFileError: errors.New("some error"),
+		"error case with get branch error": {
+			getBranchError: errors.New("some error"),
+			expectError:    true,
+		},
+		"error case with get commit error": {
+			getCommitError: errors.New("some error"),
+			expectError:    true,
+		},
+		"error case with get tree error": {
+			getTreeError: errors.New("some error"),
+			expectError:  true,
+		},
+		"error case with create blob error": {
+			createBlobError: errors.New("some error"),
+			expectError:     true,
+		},
+		"error case with create tree error": {
+			createTreeError: errors.New("some error"),
 			expectError:     true,
 		},
+		"error case with create commit error": {
+			createCommitError: errors.New("some error"),
+			expectError:       true,
+		},
+		"error case with update ref error": {
+			updateRefError: errors.New("some error"),
+			expectError:    true,
+		},
 	}
 
 	for name, c := range cases {
@@ -379,55 +695,178 @@ func TestUpdateFile(t *testing.T) {
 			githubMockClient := github_mocks.NewMockClient(ctrl)
 
 			// define mock behavior
-			githubMockClient.EXPECT().UpdateFile(gomock.Any(), gomock.Any()).
+			githubMockClient.EXPECT().GetBranch(gomock.Any(), legacy.GetBranchRequest{
+				Owner:      "some owner",
+				Repo:       "some name",
+				BranchName: "refs/heads/release/k3s-v1.23.5-update",
+			}).
+				Times(1).
+				Return(&github.Reference{
+					Object: &github.GitObject{SHA: github.String("parent sha")},
+				}, nil, c.getBranchError)
+			githubMockClient.EXPECT().GetCommit(gomock.Any(), legacy.GetCommitRequest{
+				Owner: "some owner",
+				Repo:  "some name",
+				SHA:   "parent sha",
+			}).
+				MaxTimes(1).
+				Return(&github.Commit{
+					SHA:  github.String("parent sha"),
+					Tree: &github.Tree{SHA: github.String("base tree sha")},
+				}, nil, c.getCommitError)
+			githubMockClient.EXPECT().GetTree(gomock.Any(), legacy.GetTreeRequest{
+				Owner:     "some owner",
+				Repo:      "some name",
+				SHA:       "base tree sha",
+				Recursive: true,
+			}).
 				MaxTimes(1).
-				Return(nil, nil, c.updateFileError)
+				Return(&github.Tree{
+					Entries: []*github.TreeEntry{
+						{Path: github.String("inventory/prod/group_vars/all.yml"), Mode: github.String("100644"), Type: github.String("blob")},
+						{Path: github.String("inventory/lab/group_vars/all.yml"), Mode: github.String("100755"), Type: github.String("blob")},
+					},
+				}, nil, c.getTreeError)
+			githubMockClient.EXPECT().CreateBlob(gomock.Any(), gomock.Any()).
+				MaxTimes(2).
+				DoAndReturn(func(_ context.Context, req legacy.CreateBlobRequest) (*github.Blob, *github.Response, error) {
+					content, _ := base64.StdEncoding.DecodeString(req.GetContent())
+					return &github.Blob{SHA: github.String("sha of " + string(content))}, nil, c.createBlobError
+				})
+			githubMockClient.EXPECT().CreateTree(gomock.Any(), legacy.CreateTreeRequest{
+				Owner:    "some owner",
+				Repo:     "some name",
+				BaseTree: "base tree sha",
+				Entries: []*github.TreeEntry{
+					{
+						Path: github.String("inventory/prod/group_vars/all.yml"),
+						Mode: github.String("100644"),
+						Type: github.String("blob"),
+						SHA:  github.String("sha of prod content"),
+					},
+					{
+						Path: github.String("inventory/lab/group_vars/all.yml"),
+						Mode: github.String("100755"),
+						Type: github.String("blob"),
+						SHA:  github.String("sha of lab content"),
+					},
+				},
+			}).
+				MaxTimes(1).
+				Return(&github.Tree{SHA: github.String("tree sha")}, nil, c.createTreeError)
+			githubMockClient.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+				MaxTimes(1).
+				DoAndReturn(func(_ context.Context, req legacy.CreateCommitRequest) (*github.Commit, *github.Response, error) {
+					if req.Tree.GetSHA() != "tree sha" {
+						t.Errorf("unexpected tree %q", req.Tree.GetSHA())
+					}
+					if len(req.Parents) != 1 || req.Parents[0].GetSHA() != "parent sha" {
+						t.Errorf("unexpected parents %v", req.Parents)
+					}
+					return &github.Commit{SHA: github.String("commit sha")}, nil, c.createCommitError
+				})
+			githubMockClient.EXPECT().UpdateRef(gomock.Any(), legacy.UpdateRefRequest{
+				Owner: "some owner",
+				Repo:  "some name",
+				Reference: &github.Reference{
+					Ref:    github.String("refs/heads/release/k3s-v1.23.5-update"),
+					Object: &github.GitObject{SHA: github.String("commit sha")},
+				},
+			}).
+				MaxTimes(1).
+				Return(nil, nil, c.updateRefError)
 
 			// create mock updater client
 			client := NewClient(context.Background(), Dependencies{
 				Client: githubMockClient,
 			})
 
-			err := client.updateFile(context.Background(), updateFileReq{
-				latestRelease: &github.RepositoryRelease{
-					Name: github.String("some branch name"),
-				},
-				fileContent:    "some file content",
-				currentVersion: "v1.23.4",
-				branchName:     "main",
-				repoContent: &github.RepositoryContent{
-					SHA: github.String("some sha"),
+			latestRelease := &github.RepositoryRelease{Name: github.String("v1.23.5")}
+			err := client.commitFiles(context.Background(), commitFilesReq{
+				branchName: "release/k3s-v1.23.5-update",
+				files: []*versionFile{
+					{
+						path:           "inventory/prod/group_vars/all.yml",
+						currentVersion: "v1.23.4",
+						latestRelease:  latestRelease,
+						updatedContent: []byte("prod content"),
+					},
+					{
+						path:           "inventory/lab/group_vars/all.yml",
+						currentVersion: "v1.23.3",
+						latestRelease:  latestRelease,
+						updatedContent: []byte("lab content"),
+					},
 				},
 				UpdateReleaseReq: UpdateReleaseReq{
 					Repo: Repository{
 						Owner:  "some owner",
 						Name:   "some name",
-						Path:   "/some/existing/path",
 						Branch: "main",
 					},
-					ReleaseRepo: Repository{
-						Owner: "k3s-io",
-						Name:  "k3s",
-					},
 				},
 			})
 			if c.expectError && err == nil {
 				t.FailNow()
 			}
+			if !c.expectError && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestCommitMessage(t *testing.T) {
+	latestRelease := &github.RepositoryRelease{Name: github.String("v1.23.5")}
+	cases := map[string]struct {
+		files []*versionFile
+
+		expected string
+	}{
+		"single file": {
+			files: []*versionFile{
+				{path: "all.yml", currentVersion: "v1.23.4", latestRelease: latestRelease},
+			},
+			expected: "Updated k3s version v1.23.4 to v1.23.5.",
+		},
+		"several files": {
+			files: []*versionFile{
+				{path: "prod/all.yml", currentVersion: "v1.23.4", latestRelease: latestRelease},
+				{path: "lab/all.yml", currentVersion: "v1.23.3", latestRelease: latestRelease},
+			},
+			expected: "Updated k3s version in 2 files.\n\n- prod/all.yml: v1.23.4 to v1.23.5.\n- lab/all.yml: v1.23.3 to v1.23.5.",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if message := commitMessage(c.files); message != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, message)
+			}
 		})
 	}
 }
 
 func TestCreatePR(t *testing.T) {
 	cases := map[string]struct {
-		createPRError error
-		expectError   bool
+		reviewers             []string
+		createPRError         error
+		requestReviewersError error
+		expectError           bool
 	}{
 		"success case with no error": {},
+		"success case with reviewers": {
+			reviewers: []string{"cguertin14"},
+		},
 		"error case with update file error": {
 			createPRError: errors.New("some error"),
 			expectError:   true,
 		},
+		"error case with request reviewers error": {
+			reviewers:             []string{"cguertin14"},
+			requestReviewersError: errors.New("some error"),
+			expectError:           true,
+		},
 	}
 
 	for name, c := range cases {
@@ -441,7 +880,17 @@ func TestCreatePR(t *testing.T) {
 			// define mock behavior
 			githubMockClient.EXPECT().CreatePullRequest(gomock.Any(), gomock.Any()).
 				Times(1).
-				Return(nil, nil, c.createPRError)
+				Return(&github.PullRequest{Number: github.Int(12)}, nil, c.createPRError)
+			if len(c.reviewers) > 0 {
+				githubMockClient.EXPECT().RequestReviewers(gomock.Any(), legacy.RequestReviewersRequest{
+					Owner:            "some owner",
+					Repo:             "some name",
+					Number:           12,
+					ReviewersRequest: github.ReviewersRequest{Reviewers: c.reviewers},
+				}).
+					Times(1).
+					Return(nil, nil, c.requestReviewersError)
+			}
 
 			// create mock updater client
 			client := NewClient(context.Background(), Dependencies{
@@ -465,11 +914,15 @@ func TestCreatePR(t *testing.T) {
 						Owner: "k3s-io",
 						Name:  "k3s",
 					},
+					Reviewers: c.reviewers,
 				},
 			})
 			if c.expectError && err == nil {
 				t.FailNow()
 			}
+			if !c.expectError && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
 		})
 	}
 }
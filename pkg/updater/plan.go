@@ -0,0 +1,146 @@
+package updater
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// upgradePlanGroup is the api group of the
+// system-upgrade-controller resources.
+const upgradePlanGroup string = "upgrade.cattle.io/"
+
+// upgradePlanManager updates the version of
+// system-upgrade-controller Plan manifests.
+// Server and agent plans of a file are all
+// updated to the same version.
+type upgradePlanManager struct{}
+
+// upgradePlan is a Plan document of a manifest.
+type upgradePlan struct {
+	name string
+
+	// version is nil when the
+	// plan follows a channel.
+	version *yamlScalar
+	channel string
+}
+
+// isUpgradePlan
+//
+// Reports whether a yaml file holds
+// at least one upgrade Plan.
+func isUpgradePlan(content []byte) bool {
+	plans, err := upgradePlans(content)
+	return err == nil && len(plans) > 0
+}
+
+// upgradePlans
+//
+// Returns every Plan document of a yaml stream,
+// other documents (i.e.: the namespace or the
+// controller deployment) being ignored.
+func upgradePlans(content []byte) ([]upgradePlan, error) {
+	docs, err := yamlDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]upgradePlan, 0)
+	for _, doc := range docs {
+		apiVersion := lookupYAMLNode(doc, []string{"apiVersion"})
+		kind := lookupYAMLNode(doc, []string{"kind"})
+		if apiVersion == nil || kind == nil || !strings.HasPrefix(apiVersion.Value, upgradePlanGroup) || kind.Value != "Plan" {
+			continue
+		}
+
+		plan := upgradePlan{}
+		if name := lookupYAMLNode(doc, []string{"metadata", "name"}); name != nil {
+			plan.name = name.Value
+		}
+		if channel := lookupYAMLNode(doc, []string{"spec", "channel"}); channel != nil {
+			plan.channel = channel.Value
+		}
+
+		versionPath := []string{"spec", "version"}
+		if version := lookupYAMLNode(doc, versionPath); version != nil {
+			if plan.version, err = locateYAMLScalar(content, version, versionPath); err != nil {
+				return nil, fmt.Errorf("error when reading plan %q: %s", plan.name, err)
+			}
+		}
+
+		plans = append(plans, plan)
+	}
+
+	return plans, nil
+}
+
+// pinnedPlans
+//
+// Returns the plans pinning a version. Plans
+// following a channel are upgraded by the
+// controller itself, so they are left as is.
+func pinnedPlans(content []byte) ([]upgradePlan, error) {
+	plans, err := upgradePlans(content)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned := make([]upgradePlan, 0, len(plans))
+	for _, plan := range plans {
+		if plan.version != nil {
+			pinned = append(pinned, plan)
+		}
+	}
+
+	switch {
+	case len(plans) == 0:
+		return nil, errors.New("no upgrade plan found")
+	case len(pinned) == 0:
+		return nil, fmt.Errorf("plan %q follows the %q channel and pins no version", plans[0].name, plans[0].channel)
+	}
+
+	return pinned, nil
+}
+
+func (upgradePlanManager) extract(_ UpdateReleaseReq, content []byte) (string, error) {
+	plans, err := pinnedPlans(content)
+	if err != nil {
+		return "", err
+	}
+
+	versions := make([]string, 0, len(plans))
+	for _, plan := range plans {
+		versions = append(versions, plan.version.node.Value)
+	}
+
+	return oldestK3sVersion(versions), nil
+}
+
+func (upgradePlanManager) update(req updateFileReq) ([]byte, error) {
+	content := []byte(req.fileContent)
+	plans, err := pinnedPlans(content)
+	if err != nil {
+		return nil, err
+	}
+
+	scalars := make([]*yamlScalar, 0, len(plans))
+	for _, plan := range plans {
+		scalars = append(scalars, plan.version)
+	}
+
+	return replaceYAMLScalars(content, scalars, *req.latestRelease.Name), nil
+}
+
+func (upgradePlanManager) scan(_ string, content []byte) []versionPin {
+	plans, err := pinnedPlans(content)
+	if err != nil {
+		return nil
+	}
+
+	pins := make([]versionPin, 0, len(plans))
+	for _, plan := range plans {
+		pins = append(pins, versionPin{key: "spec.version", version: plan.version.node.Value})
+	}
+	return pins
+}
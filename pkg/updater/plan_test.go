@@ -0,0 +1,150 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestUpgradePlanManager(t *testing.T) {
+	manifest, err := os.ReadFile(filepath.Join("testdata", "plan", "k3s-upgrade.input.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile(filepath.Join("testdata", "plan", "k3s-upgrade.golden.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		content string
+
+		expectedVersion string
+		expectedContent string
+		expectError     bool
+	}{
+		"success case with server and agent plans": {
+			content:         string(manifest),
+			expectedVersion: "v1.28.4+k3s2",
+			expectedContent: string(golden),
+		},
+		"success case with other documents": {
+			content: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: system-upgrade
+---
+apiVersion: upgrade.cattle.io/v1
+kind: Plan
+metadata:
+  name: server-plan
+spec:
+  version: v1.28.5+k3s1
+`,
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: `apiVersion: v1
+kind: Namespace
+metadata:
+  name: system-upgrade
+---
+apiVersion: upgrade.cattle.io/v1
+kind: Plan
+metadata:
+  name: server-plan
+spec:
+  version: v1.29.6+k3s2
+`,
+		},
+		"success case with a plan following a channel": {
+			content: `apiVersion: upgrade.cattle.io/v1
+kind: Plan
+metadata:
+  name: server-plan
+spec:
+  version: v1.28.5+k3s1
+---
+apiVersion: upgrade.cattle.io/v1
+kind: Plan
+metadata:
+  name: agent-plan
+spec:
+  channel: https://update.k3s.io/v1-release/channels/stable
+`,
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: `apiVersion: upgrade.cattle.io/v1
+kind: Plan
+metadata:
+  name: server-plan
+spec:
+  version: v1.29.6+k3s2
+---
+apiVersion: upgrade.cattle.io/v1
+kind: Plan
+metadata:
+  name: agent-plan
+spec:
+  channel: https://update.k3s.io/v1-release/channels/stable
+`,
+		},
+		"error case with only channels": {
+			content: `apiVersion: upgrade.cattle.io/v1
+kind: Plan
+metadata:
+  name: server-plan
+spec:
+  channel: https://update.k3s.io/v1-release/channels/stable
+`,
+			expectError: true,
+		},
+		"error case with no plan": {
+			content: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: system-upgrade-controller
+`,
+			expectError: true,
+		},
+		"error case with invalid yaml": {
+			content:     "kind: [Plan",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := upgradePlanManager{}
+
+			version, err := m.extract(UpdateReleaseReq{}, []byte(c.content))
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if version != c.expectedVersion {
+				t.Fatalf("expected version %q, got %q", c.expectedVersion, version)
+			}
+
+			updated, err := m.update(updateFileReq{
+				fileContent: c.content,
+				latestRelease: &github.RepositoryRelease{
+					Name: github.String("v1.29.6+k3s2"),
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(updated) != c.expectedContent {
+				t.Fatalf("expected:\n%s\ngot:\n%s", c.expectedContent, updated)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package updater
+
+import (
+	"fmt"
+
+	"golang.org/x/mod/semver"
+)
+
+// UpdatePolicy defines how far from the current
+// version an update is allowed to go.
+type UpdatePolicy string
+
+const (
+	// UpdatePolicyPatch only allows updates
+	// within the current minor version.
+	UpdatePolicyPatch UpdatePolicy = "patch"
+
+	// UpdatePolicyMinor only allows updates
+	// within the current major version.
+	UpdatePolicyMinor UpdatePolicy = "minor"
+
+	// UpdatePolicyMajor allows any update.
+	UpdatePolicyMajor UpdatePolicy = "major"
+)
+
+// validate
+//
+// Returns an error when the policy is unknown. An
+// empty policy is valid and behaves like "major".
+func (p UpdatePolicy) validate() error {
+	switch p {
+	case "", UpdatePolicyPatch, UpdatePolicyMinor, UpdatePolicyMajor:
+		return nil
+	default:
+		return fmt.Errorf("unknown update policy %q", p)
+	}
+}
+
+// allows
+//
+// Returns whether the policy allows an
+// update from current to candidate.
+func (p UpdatePolicy) allows(current, candidate string) bool {
+	switch p {
+	case UpdatePolicyPatch:
+		return semver.MajorMinor(current) == semver.MajorMinor(candidate)
+	case UpdatePolicyMinor:
+		return semver.Major(current) == semver.Major(candidate)
+	default:
+		return true
+	}
+}
@@ -0,0 +1,432 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	"github.com/cguertin14/logger"
+	"github.com/google/go-github/v57/github"
+	"golang.org/x/mod/semver"
+)
+
+// ReleaseSource is the github datasource used
+// to find the versions of the release repository.
+type ReleaseSource string
+
+const (
+	// ReleaseSourceReleases uses every github release.
+	ReleaseSourceReleases ReleaseSource = "releases"
+
+	// ReleaseSourceTags uses every git tag, for
+	// repositories that don't publish releases.
+	ReleaseSourceTags ReleaseSource = "tags"
+
+	// ReleaseSourceLatest only uses the release
+	// marked as latest on github.
+	ReleaseSourceLatest ReleaseSource = "latest"
+)
+
+// getCandidateReleases
+//
+// Returns the releases that can be proposed
+// as an update, from the configured datasource.
+// The channel, when set, takes precedence over
+// the release source.
+func (c *ClientSet) getCandidateReleases(ctx context.Context, req UpdateReleaseReq) ([]*github.RepositoryRelease, error) {
+	if req.Channel != "" {
+		release, err := c.getChannelRelease(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return []*github.RepositoryRelease{release}, nil
+	}
+
+	switch req.ReleaseSource {
+	case "", ReleaseSourceReleases:
+		return c.listK3sReleases(ctx, req)
+	case ReleaseSourceTags:
+		return c.listK3sTags(ctx, req)
+	case ReleaseSourceLatest:
+		release, _, err := c.client.GetLatestRelease(ctx, legacy.CommonRequest{
+			Owner: req.ReleaseRepo.Owner,
+			Repo:  req.ReleaseRepo.Name,
+		})
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error when fetching latest release from %s/%s: %s",
+				req.ReleaseRepo.Owner,
+				req.ReleaseRepo.Name,
+				err,
+			)
+		}
+		return []*github.RepositoryRelease{release}, nil
+	default:
+		return nil, fmt.Errorf("error when fetching releases: unknown release source %q", req.ReleaseSource)
+	}
+}
+
+// listK3sReleases
+//
+// Walks every page of releases published
+// on the release repository.
+func (c *ClientSet) listK3sReleases(ctx context.Context, req UpdateReleaseReq) ([]*github.RepositoryRelease, error) {
+	releases := make([]*github.RepositoryRelease, 0)
+	page := 0
+	for {
+		pageReleases, resp, err := c.client.GetRepositoryReleases(ctx, legacy.ListRequest{
+			Owner: req.ReleaseRepo.Owner,
+			Repo:  req.ReleaseRepo.Name,
+			Page:  page,
+		})
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error when fetching releases from %s/%s: %s",
+				req.ReleaseRepo.Owner,
+				req.ReleaseRepo.Name,
+				err,
+			)
+		}
+		releases = append(releases, pageReleases...)
+
+		if resp == nil || resp.NextPage == 0 {
+			return releases, nil
+		}
+		page = resp.NextPage
+	}
+}
+
+// listK3sTags
+//
+// Walks every page of tags pushed on the
+// release repository, and turns them into
+// releases named after the tags.
+func (c *ClientSet) listK3sTags(ctx context.Context, req UpdateReleaseReq) ([]*github.RepositoryRelease, error) {
+	releases := make([]*github.RepositoryRelease, 0)
+	page := 0
+	for {
+		tags, resp, err := c.client.GetRepositoryTags(ctx, legacy.ListRequest{
+			Owner: req.ReleaseRepo.Owner,
+			Repo:  req.ReleaseRepo.Name,
+			Page:  page,
+		})
+		if err != nil {
+			return nil, fmt.Errorf(
+				"error when fetching tags from %s/%s: %s",
+				req.ReleaseRepo.Owner,
+				req.ReleaseRepo.Name,
+				err,
+			)
+		}
+
+		for _, tag := range tags {
+			releases = append(releases, &github.RepositoryRelease{
+				Name:       github.String(tag.GetName()),
+				TagName:    github.String(tag.GetName()),
+				Prerelease: github.Bool(false),
+				Body: github.String(fmt.Sprintf(
+					"https://github.com/%s/%s/tree/%s",
+					req.ReleaseRepo.Owner,
+					req.ReleaseRepo.Name,
+					tag.GetName(),
+				)),
+			})
+		}
+
+		if resp == nil || resp.NextPage == 0 {
+			return releases, nil
+		}
+		page = resp.NextPage
+	}
+}
+
+// sortReleases
+//
+// Sorts releases from the newest
+// version to the oldest one.
+func sortReleases(releases []*github.RepositoryRelease) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		return compareK3sVersions(releases[i].GetName(), releases[j].GetName()) > 0
+	})
+}
+
+// skippedRelease is a newer release which was
+// deliberately held back by the configuration.
+type skippedRelease struct {
+	name   string
+	reason string
+}
+
+type deniedVersions struct {
+	raw        string
+	constraint versionConstraint
+}
+
+// eligibleReleases
+//
+// Keeps the releases which can be proposed as an
+// update of the current version, sorted from the
+// newest version to the oldest one. Releases held
+// back by the denylist or the pinned version are
+// returned as well, so they can be reported.
+func eligibleReleases(ctx context.Context, req UpdateReleaseReq, currentVersion string, releases []*github.RepositoryRelease) (eligible []*github.RepositoryRelease, skipped []skippedRelease, err error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+
+	if err = req.UpdatePolicy.validate(); err != nil {
+		return nil, nil, fmt.Errorf("error when filtering releases: %s", err)
+	}
+
+	var constraint versionConstraint
+	if req.VersionConstraint != "" {
+		if constraint, err = parseVersionConstraint(req.VersionConstraint); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	denylist := make([]deniedVersions, 0, len(req.DeniedVersions))
+	for _, raw := range req.DeniedVersions {
+		denied, err := parseVersionConstraint(raw)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error when parsing denied versions: %s", err)
+		}
+		denylist = append(denylist, deniedVersions{raw: raw, constraint: denied})
+	}
+
+	var pin versionConstraint
+	if req.PinnedVersion != "" {
+		if pin, err = parseVersionConstraint("<=" + req.PinnedVersion); err != nil {
+			return nil, nil, fmt.Errorf("error when parsing pinned version: %s", err)
+		}
+	}
+
+	assets, err := requiredAssets(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(assets) > 0 && req.Channel == "" && req.ReleaseSource == ReleaseSourceTags {
+		// tags don't have any asset attached to them
+		logger.Warnf("Release assets can't be verified when using the %q release source.", ReleaseSourceTags)
+		assets = nil
+	}
+
+	minReleaseAge := req.MinReleaseAge
+	if minReleaseAge > 0 && req.Channel == "" && req.ReleaseSource == ReleaseSourceTags {
+		// tags don't have any publication date
+		logger.Warnf("The release age can't be verified when using the %q release source.", ReleaseSourceTags)
+		minReleaseAge = 0
+	}
+
+	eligible = make([]*github.RepositoryRelease, 0)
+	skipped = make([]skippedRelease, 0)
+releasesLoop:
+	for _, r := range releases {
+		// Exclude releases that aren't named
+		// after a valid semantic version
+		if !semver.IsValid(r.GetName()) {
+			continue
+		}
+
+		// Exclude releases which aren't newer than
+		// the current version, since there is no
+		// need to update to them.
+		if compareK3sVersions(currentVersion, *r.Name) >= 0 {
+			continue
+		}
+
+		// Exclude pre-releases (release candidates, alphas,
+		// betas, etc.), since they're not fully ready yet,
+		// unless they've been explicitly allowed.
+		if isPrerelease(r) && !req.AllowPrereleases {
+			logger.Warnf("A new pre-release is available: %q\n", *r.Name)
+			continue
+		}
+
+		if !req.UpdatePolicy.allows(currentVersion, *r.Name) {
+			logger.Infof("Skipping release %q: not allowed by the %q update policy.", *r.Name, req.UpdatePolicy)
+			continue
+		}
+
+		if constraint != nil && !constraint.check(*r.Name) {
+			logger.Infof("Skipping release %q: does not satisfy the %q version constraint.", *r.Name, req.VersionConstraint)
+			continue
+		}
+
+		// Releases of a channel missing from github have
+		// unknown assets and age, and are only proposed
+		// unverified when explicitly allowed.
+		unverifiable := isChannelFallback(req, r)
+		if unverifiable && (minReleaseAge > 0 || len(assets) > 0) {
+			if !req.AllowUnverifiedChannelRelease {
+				logger.Warnf("Skipping release %q: missing from github, so its assets and publication date can't be verified.", *r.Name)
+				continue
+			}
+			logger.Warnf("Release %q of the %q channel is missing from github, proposing it without verifying its assets and publication date.", *r.Name, req.Channel)
+		}
+
+		if minReleaseAge > 0 && !unverifiable {
+			if r.PublishedAt == nil {
+				logger.Infof("Skipping release %q: its publication date is unknown.", *r.Name)
+				continue
+			}
+			if age := time.Since(r.PublishedAt.Time); age < minReleaseAge {
+				logger.Infof(
+					"Skipping release %q: published %s ago, which is less than the %s minimum release age.",
+					*r.Name,
+					age.Round(time.Minute),
+					minReleaseAge,
+				)
+				continue
+			}
+		}
+
+		for _, denied := range denylist {
+			if denied.constraint.check(*r.Name) {
+				reason := fmt.Sprintf("denied by %q", denied.raw)
+				logger.Infof("Skipping release %q: %s.", *r.Name, reason)
+				skipped = append(skipped, skippedRelease{name: *r.Name, reason: reason})
+				continue releasesLoop
+			}
+		}
+
+		if pin != nil && !pin.check(*r.Name) {
+			reason := fmt.Sprintf("above the pinned version %q", req.PinnedVersion)
+			logger.Infof("Skipping release %q: %s.", *r.Name, reason)
+			skipped = append(skipped, skippedRelease{name: *r.Name, reason: reason})
+			continue
+		}
+
+		if missing := missingAssets(r, assets); len(missing) > 0 && !unverifiable {
+			logger.Infof("Skipping release %q: missing assets %s.", *r.Name, strings.Join(missing, ", "))
+			continue
+		}
+
+		eligible = append(eligible, r)
+	}
+	sortReleases(eligible)
+
+	return eligible, skipped, nil
+}
+
+// isChannelFallback
+//
+// Returns whether a release was resolved from
+// a channel while missing from github, in which
+// case it was built from the channel version alone
+// and has no id, assets or publication date.
+func isChannelFallback(req UpdateReleaseReq, r *github.RepositoryRelease) bool {
+	return req.Channel != "" && r.ID == nil
+}
+
+// skippedNote
+//
+// Describes the releases newer than the proposed
+// one which were held back by the configuration.
+// Returns an empty string when there is none.
+func skippedNote(proposed string, skipped []skippedRelease) string {
+	sort.SliceStable(skipped, func(i, j int) bool {
+		return compareK3sVersions(skipped[i].name, skipped[j].name) > 0
+	})
+
+	lines := make([]string, 0, len(skipped))
+	for _, s := range skipped {
+		if compareK3sVersions(s.name, proposed) > 0 {
+			lines = append(lines, fmt.Sprintf("- `%s`: %s", s.name, s.reason))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"**Skipped versions**: the following newer versions were held back by the configuration.\n\n%s",
+		strings.Join(lines, "\n"),
+	)
+}
+
+// isPrerelease
+//
+// Returns whether a release is a pre-release, either
+// from its semver pre-release component (i.e.: -rc1,
+// -alpha.1) or because it's flagged as such on github.
+func isPrerelease(r *github.RepositoryRelease) bool {
+	return semver.Prerelease(r.GetName()) != "" || r.GetPrerelease()
+}
+
+// nextMinor
+//
+// Returns the minor version following the one
+// of a version (i.e.: v1.29 for v1.28.5+k3s1).
+func nextMinor(version string) string {
+	var major, minor int
+	if _, err := fmt.Sscanf(semver.MajorMinor(version), "v%d.%d", &major, &minor); err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("v%d.%d", major, minor+1)
+}
+
+// nextMinorRelease
+//
+// Picks the newest release of the minor version
+// following the current one, since Kubernetes can't
+// skip a minor version. When there is none, the newest
+// patch release of the current minor version is picked
+// instead, or nil if there is none either. The newest
+// release of every following minor version is returned
+// as well, from the oldest to the newest one, up to
+// the first missing one.
+func nextMinorRelease(currentVersion string, eligible []*github.RepositoryRelease) (next *github.RepositoryRelease, remaining []*github.RepositoryRelease) {
+	// eligible releases are sorted from the newest to
+	// the oldest one, so the first release of each
+	// minor version is the newest one.
+	newestPerMinor := make(map[string]*github.RepositoryRelease)
+	for _, r := range eligible {
+		minor := semver.MajorMinor(*r.Name)
+		if _, ok := newestPerMinor[minor]; !ok {
+			newestPerMinor[minor] = r
+		}
+	}
+
+	// No newer minor version: the newest
+	// patch release can be proposed.
+	if semver.Compare(semver.MajorMinor(eligible[0].GetName()), semver.MajorMinor(currentVersion)) <= 0 {
+		return eligible[0], nil
+	}
+
+	minor := nextMinor(currentVersion)
+	next, ok := newestPerMinor[minor]
+	if !ok {
+		// A patch release is always a supported upgrade.
+		return newestPerMinor[semver.MajorMinor(currentVersion)], nil
+	}
+	for {
+		minor = nextMinor(minor)
+		r, ok := newestPerMinor[minor]
+		if !ok {
+			break
+		}
+		remaining = append(remaining, r)
+	}
+
+	return next, remaining
+}
+
+// stepwiseNote
+//
+// Describes the upgrades left to go
+// through after a stepwise upgrade.
+func stepwiseNote(remaining []*github.RepositoryRelease) string {
+	hops := make([]string, 0, len(remaining))
+	for _, r := range remaining {
+		hops = append(hops, fmt.Sprintf("`%s`", *r.Name))
+	}
+
+	return fmt.Sprintf(
+		"**Stepwise upgrade**: Kubernetes can't skip minor versions, so this is one hop out of %d. Remaining upgrades, one minor version at a time: %s.",
+		len(remaining)+1,
+		strings.Join(hops, " → "),
+	)
+}
@@ -0,0 +1,497 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	github_mocks "github.com/cguertin14/k3supdater/pkg/github/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v57/github"
+)
+
+func newTestRelease(name string) *github.RepositoryRelease {
+	return &github.RepositoryRelease{
+		Body:       github.String("some release notes"),
+		Name:       github.String(name),
+		Prerelease: github.Bool(false),
+	}
+}
+
+func releaseNames(releases []*github.RepositoryRelease) []string {
+	names := make([]string, 0, len(releases))
+	for _, r := range releases {
+		names = append(names, r.GetName())
+	}
+	return names
+}
+
+func TestEligibleReleases(t *testing.T) {
+	releases := []*github.RepositoryRelease{
+		newTestRelease("v1.28.10+k3s1"),
+		newTestRelease("v1.30.2+k3s1"),
+		newTestRelease("v1.28.11+k3s1"),
+		newTestRelease("v1.29.6+k3s1"),
+		newTestRelease("v1.27.15+k3s1"),
+		newTestRelease("v2.0.0+k3s1"),
+		newTestRelease("v1.31.0-rc1+k3s1"),
+		newTestRelease("v1.31.0-alpha.1+k3s1"),
+		newTestRelease("v1.30.3+k3s1"),
+	}
+	releases[len(releases)-1].Prerelease = github.Bool(true)
+
+	// v1.30.2 and v1.28.11 are too recent, and v2.0.0
+	// doesn't have a publication date at all.
+	publishedAgo := map[string]time.Duration{
+		"v1.28.10+k3s1": 30 * 24 * time.Hour,
+		"v1.30.2+k3s1":  2 * time.Hour,
+		"v1.28.11+k3s1": 71 * time.Hour,
+		"v1.29.6+k3s1":  73 * time.Hour,
+		"v1.27.15+k3s1": 60 * 24 * time.Hour,
+	}
+	for _, r := range releases {
+		if ago, ok := publishedAgo[r.GetName()]; ok {
+			r.PublishedAt = &github.Timestamp{Time: time.Now().Add(-ago)}
+		}
+	}
+
+	cases := map[string]struct {
+		currentVersion string
+		req            UpdateReleaseReq
+
+		expected        []string
+		expectedSkipped []string
+		expectError     bool
+	}{
+		"success case with default policy": {
+			currentVersion: "v1.28.10+k3s1",
+			expected:       []string{"v2.0.0+k3s1", "v1.30.2+k3s1", "v1.29.6+k3s1", "v1.28.11+k3s1"},
+		},
+		"success case with patch policy": {
+			currentVersion: "v1.28.9+k3s1",
+			req:            UpdateReleaseReq{UpdatePolicy: UpdatePolicyPatch},
+			expected:       []string{"v1.28.11+k3s1", "v1.28.10+k3s1"},
+		},
+		"success case with minor policy": {
+			currentVersion: "v1.28.9+k3s1",
+			req:            UpdateReleaseReq{UpdatePolicy: UpdatePolicyMinor},
+			expected:       []string{"v1.30.2+k3s1", "v1.29.6+k3s1", "v1.28.11+k3s1", "v1.28.10+k3s1"},
+		},
+		"success case with version constraint": {
+			currentVersion: "v1.27.15+k3s1",
+			req:            UpdateReleaseReq{VersionConstraint: ">=v1.28.11 <v1.30.0"},
+			expected:       []string{"v1.29.6+k3s1", "v1.28.11+k3s1"},
+		},
+		"success case with policy and version constraint": {
+			currentVersion: "v1.28.9+k3s1",
+			req: UpdateReleaseReq{
+				UpdatePolicy:      UpdatePolicyPatch,
+				VersionConstraint: "<v1.28.11",
+			},
+			expected: []string{"v1.28.10+k3s1"},
+		},
+		"success case with minimum release age": {
+			currentVersion: "v1.27.15+k3s1",
+			req:            UpdateReleaseReq{MinReleaseAge: 72 * time.Hour},
+			expected:       []string{"v1.29.6+k3s1", "v1.28.10+k3s1"},
+		},
+		"success case with minimum release age and tags": {
+			currentVersion: "v1.28.11+k3s1",
+			req: UpdateReleaseReq{
+				ReleaseSource: ReleaseSourceTags,
+				MinReleaseAge: 72 * time.Hour,
+			},
+			expected: []string{"v2.0.0+k3s1", "v1.30.2+k3s1", "v1.29.6+k3s1"},
+		},
+		"success case with pre-releases allowed": {
+			currentVersion: "v1.30.2+k3s1",
+			req:            UpdateReleaseReq{AllowPrereleases: true},
+			expected:       []string{"v2.0.0+k3s1", "v1.31.0-rc1+k3s1", "v1.31.0-alpha.1+k3s1", "v1.30.3+k3s1"},
+		},
+		"success case with pre-releases excluded": {
+			currentVersion: "v1.30.2+k3s1",
+			expected:       []string{"v2.0.0+k3s1"},
+		},
+		"success case with current pre-release": {
+			currentVersion: "v1.31.0-alpha.1+k3s1",
+			req: UpdateReleaseReq{
+				AllowPrereleases: true,
+				UpdatePolicy:     UpdatePolicyPatch,
+			},
+			expected: []string{"v1.31.0-rc1+k3s1"},
+		},
+		"success case with denied versions": {
+			currentVersion:  "v1.27.15+k3s1",
+			req:             UpdateReleaseReq{DeniedVersions: []string{"v2.0.0", ">=v1.29.0 <v1.30.0"}},
+			expected:        []string{"v1.30.2+k3s1", "v1.28.11+k3s1", "v1.28.10+k3s1"},
+			expectedSkipped: []string{"v1.29.6+k3s1", "v2.0.0+k3s1"},
+		},
+		"success case with pinned version": {
+			currentVersion:  "v1.27.15+k3s1",
+			req:             UpdateReleaseReq{PinnedVersion: "v1.28.11+k3s1"},
+			expected:        []string{"v1.28.11+k3s1", "v1.28.10+k3s1"},
+			expectedSkipped: []string{"v1.30.2+k3s1", "v1.29.6+k3s1", "v2.0.0+k3s1"},
+		},
+		"error case with invalid denied version": {
+			currentVersion: "v1.28.9+k3s1",
+			req:            UpdateReleaseReq{DeniedVersions: []string{"latest"}},
+			expectError:    true,
+		},
+		"error case with invalid pinned version": {
+			currentVersion: "v1.28.9+k3s1",
+			req:            UpdateReleaseReq{PinnedVersion: "latest"},
+			expectError:    true,
+		},
+		"error case with unknown policy": {
+			currentVersion: "v1.28.9+k3s1",
+			req:            UpdateReleaseReq{UpdatePolicy: "some policy"},
+			expectError:    true,
+		},
+		"error case with invalid version constraint": {
+			currentVersion: "v1.28.9+k3s1",
+			req:            UpdateReleaseReq{VersionConstraint: ">=latest"},
+			expectError:    true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			eligible, skipped, err := eligibleReleases(context.Background(), c.req, c.currentVersion, releases)
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := releaseNames(eligible)
+			if len(got) != len(c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+			for i := range got {
+				if got[i] != c.expected[i] {
+					t.Fatalf("expected %v, got %v", c.expected, got)
+				}
+			}
+
+			if c.expectedSkipped != nil {
+				gotSkipped := make([]string, 0, len(skipped))
+				for _, s := range skipped {
+					gotSkipped = append(gotSkipped, s.name)
+				}
+				if strings.Join(gotSkipped, ",") != strings.Join(c.expectedSkipped, ",") {
+					t.Fatalf("expected skipped %v, got %v", c.expectedSkipped, gotSkipped)
+				}
+			}
+		})
+	}
+}
+
+func TestEligibleReleasesChannelFallback(t *testing.T) {
+	// as built by getChannelRelease when
+	// the release is missing from github
+	fallback := newTestRelease("v1.29.6+k3s1")
+
+	// as fetched from github
+	fetched := newTestRelease("v1.29.6+k3s1")
+	fetched.ID = github.Int64(1)
+
+	cases := map[string]struct {
+		release *github.RepositoryRelease
+		req     UpdateReleaseReq
+
+		expected []string
+	}{
+		"success case with unverifiable channel release": {
+			release: fallback,
+			req: UpdateReleaseReq{
+				Channel:       "stable",
+				Architectures: []string{"amd64", "arm64", "arm"},
+				MinReleaseAge: 72 * time.Hour,
+			},
+			expected: []string{},
+		},
+		"success case with unverifiable channel release allowed": {
+			release: fallback,
+			req: UpdateReleaseReq{
+				Channel:                       "stable",
+				AllowUnverifiedChannelRelease: true,
+				Architectures:                 []string{"amd64", "arm64", "arm"},
+				MinReleaseAge:                 72 * time.Hour,
+			},
+			expected: []string{"v1.29.6+k3s1"},
+		},
+		"success case with channel release and nothing to verify": {
+			release: fallback,
+			req: UpdateReleaseReq{
+				Channel: "stable",
+			},
+			expected: []string{"v1.29.6+k3s1"},
+		},
+		"success case with github release missing assets": {
+			release: fetched,
+			req: UpdateReleaseReq{
+				Channel:       "stable",
+				Architectures: []string{"amd64", "arm64", "arm"},
+			},
+			expected: []string{},
+		},
+		"success case with github release missing publication date": {
+			release: fetched,
+			req: UpdateReleaseReq{
+				Channel:       "stable",
+				MinReleaseAge: 72 * time.Hour,
+			},
+			expected: []string{},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			eligible, _, err := eligibleReleases(context.Background(), c.req, "v1.28.10+k3s1", []*github.RepositoryRelease{c.release})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if got := releaseNames(eligible); strings.Join(got, ",") != strings.Join(c.expected, ",") {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestSkippedNote(t *testing.T) {
+	skipped := []skippedRelease{
+		{name: "v1.29.6+k3s1", reason: "denied by \"v1.29.6\""},
+		{name: "v1.30.2+k3s1", reason: "above the pinned version \"v1.29.9\""},
+		{name: "v1.28.3+k3s1", reason: "denied by \"v1.28.3\""},
+	}
+
+	note := skippedNote("v1.28.11+k3s1", skipped)
+	expected := "**Skipped versions**: the following newer versions were held back by the configuration.\n\n" +
+		"- `v1.30.2+k3s1`: above the pinned version \"v1.29.9\"\n" +
+		"- `v1.29.6+k3s1`: denied by \"v1.29.6\""
+	if note != expected {
+		t.Fatalf("expected note %q, got %q", expected, note)
+	}
+
+	if note := skippedNote("v1.31.0+k3s1", skipped); note != "" {
+		t.Fatalf("expected no note, got %q", note)
+	}
+}
+
+func TestNextMinorRelease(t *testing.T) {
+	// sorted from the newest to the oldest one,
+	// as returned by eligibleReleases.
+	eligible := []*github.RepositoryRelease{
+		newTestRelease("v1.31.1+k3s1"),
+		newTestRelease("v1.31.0+k3s1"),
+		newTestRelease("v1.30.5+k3s1"),
+		newTestRelease("v1.29.9+k3s1"),
+		newTestRelease("v1.29.8+k3s1"),
+		newTestRelease("v1.28.14+k3s1"),
+	}
+
+	cases := map[string]struct {
+		currentVersion string
+		eligible       []*github.RepositoryRelease
+
+		expected          string
+		expectedRemaining []string
+	}{
+		"several minors behind": {
+			currentVersion:    "v1.28.5+k3s1",
+			eligible:          eligible,
+			expected:          "v1.29.9+k3s1",
+			expectedRemaining: []string{"v1.30.5+k3s1", "v1.31.1+k3s1"},
+		},
+		"one minor behind": {
+			currentVersion:    "v1.30.1+k3s1",
+			eligible:          eligible[:3],
+			expected:          "v1.31.1+k3s1",
+			expectedRemaining: []string{},
+		},
+		"patch update only": {
+			currentVersion:    "v1.29.7+k3s1",
+			eligible:          eligible[4:],
+			expected:          "v1.29.8+k3s1",
+			expectedRemaining: []string{},
+		},
+		"next minor missing from eligible releases": {
+			currentVersion:    "v1.27.5+k3s1",
+			eligible:          append([]*github.RepositoryRelease{}, eligible[:3]...),
+			expected:          "",
+			expectedRemaining: []string{},
+		},
+		"only a minor two versions ahead left": {
+			currentVersion:    "v1.28.5+k3s1",
+			eligible:          []*github.RepositoryRelease{newTestRelease("v1.30.2+k3s1")},
+			expected:          "",
+			expectedRemaining: []string{},
+		},
+		"next minor missing with a newer patch": {
+			currentVersion: "v1.28.5+k3s1",
+			eligible: []*github.RepositoryRelease{
+				newTestRelease("v1.30.2+k3s1"),
+				newTestRelease("v1.28.9+k3s1"),
+			},
+			expected:          "v1.28.9+k3s1",
+			expectedRemaining: []string{},
+		},
+		"gap after the next minor": {
+			currentVersion:    "v1.28.5+k3s1",
+			eligible:          append(append([]*github.RepositoryRelease{}, eligible[:2]...), eligible[3:]...),
+			expected:          "v1.29.9+k3s1",
+			expectedRemaining: []string{},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			next, remaining := nextMinorRelease(c.currentVersion, c.eligible)
+			if next.GetName() != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, next.GetName())
+			}
+
+			got := releaseNames(remaining)
+			if len(got) != len(c.expectedRemaining) {
+				t.Fatalf("expected remaining %v, got %v", c.expectedRemaining, got)
+			}
+			for i := range got {
+				if got[i] != c.expectedRemaining[i] {
+					t.Fatalf("expected remaining %v, got %v", c.expectedRemaining, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGetCandidateReleases(t *testing.T) {
+	cases := map[string]struct {
+		source ReleaseSource
+
+		releasesPages [][]*github.RepositoryRelease
+		tagsPages     [][]*github.RepositoryTag
+		latest        *github.RepositoryRelease
+		responseError error
+
+		expected    []string
+		expectError bool
+	}{
+		"success case with releases": {
+			source: ReleaseSourceReleases,
+			releasesPages: [][]*github.RepositoryRelease{
+				{newTestRelease("v1.30.2+k3s1")},
+				{newTestRelease("v1.29.6+k3s1")},
+			},
+			expected: []string{"v1.30.2+k3s1", "v1.29.6+k3s1"},
+		},
+		"success case with default source": {
+			releasesPages: [][]*github.RepositoryRelease{
+				{newTestRelease("v1.30.2+k3s1")},
+			},
+			expected: []string{"v1.30.2+k3s1"},
+		},
+		"success case with tags": {
+			source: ReleaseSourceTags,
+			tagsPages: [][]*github.RepositoryTag{
+				{{Name: github.String("v1.30.2+k3s1")}, {Name: github.String("v1.30.1+k3s1")}},
+				{{Name: github.String("v1.29.6+k3s1")}},
+			},
+			expected: []string{"v1.30.2+k3s1", "v1.30.1+k3s1", "v1.29.6+k3s1"},
+		},
+		"success case with latest release": {
+			source:   ReleaseSourceLatest,
+			latest:   newTestRelease("v1.30.2+k3s1"),
+			expected: []string{"v1.30.2+k3s1"},
+		},
+		"error case with tags error": {
+			source:        ReleaseSourceTags,
+			tagsPages:     [][]*github.RepositoryTag{nil},
+			responseError: errors.New("some error"),
+			expectError:   true,
+		},
+		"error case with latest release error": {
+			source:        ReleaseSourceLatest,
+			responseError: errors.New("some error"),
+			expectError:   true,
+		},
+		"error case with unknown source": {
+			source:      "some source",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// create new mock client instance
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+
+			// define mock behavior, one call per page
+			for i, page := range c.releasesPages {
+				resp := &github.Response{}
+				if i < len(c.releasesPages)-1 {
+					resp.NextPage = i + 2
+				}
+				githubMockClient.EXPECT().GetRepositoryReleases(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(page, resp, c.responseError)
+			}
+			for i, page := range c.tagsPages {
+				resp := &github.Response{}
+				if i < len(c.tagsPages)-1 {
+					resp.NextPage = i + 2
+				}
+				githubMockClient.EXPECT().GetRepositoryTags(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(page, resp, c.responseError)
+			}
+			if c.source == ReleaseSourceLatest {
+				githubMockClient.EXPECT().GetLatestRelease(gomock.Any(), legacy.CommonRequest{
+					Owner: "k3s-io",
+					Repo:  "k3s",
+				}).
+					Times(1).
+					Return(c.latest, nil, c.responseError)
+			}
+
+			// create mock updater client
+			client := NewClient(context.Background(), Dependencies{
+				Client: githubMockClient,
+			})
+
+			releases, err := client.getCandidateReleases(context.Background(), UpdateReleaseReq{
+				ReleaseRepo: Repository{
+					Owner: "k3s-io",
+					Name:  "k3s",
+				},
+				ReleaseSource: c.source,
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			got := releaseNames(releases)
+			if strings.Join(got, ",") != strings.Join(c.expected, ",") {
+				t.Fatalf("expected %v, got %v", c.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/cguertin14/logger"
+	"golang.org/x/mod/semver"
+)
+
+// k3sVersionPattern matches k3s versions,
+// i.e.: v1.30.4+k3s1 or v1.31.0-rc1+k3s1.
+var k3sVersionPattern = regexp.MustCompile(`^v?\d+\.\d+\.\d+(-rc\d+)?\+k3s\d+$`)
+
+// skippedDirectories are never scanned.
+var skippedDirectories = []string{".git", ".terraform", "node_modules", "vendor"}
+
+// versionPin is a version found in
+// a file, along with its key.
+type versionPin struct {
+	key     string
+	version string
+}
+
+// Location is a k3s version pin
+// found in a repository.
+type Location struct {
+	// Path is the path of the file,
+	// from the root of the repository.
+	Path string
+
+	// Manager is the type of the file
+	// (i.e.: ansible, plan, terraform, env).
+	Manager string
+
+	// Key is the variable, or the key
+	// path, holding the version.
+	Key string
+
+	// Version is the version pinned.
+	Version string
+}
+
+// VersionKey
+//
+// Returns the version key to configure for
+// the location to be updated, or an empty
+// one when the default key applies.
+func (l Location) VersionKey() string {
+	switch l.Manager {
+	case ansibleManager:
+		if l.Key == DefaultVersionKey {
+			return ""
+		}
+	case terraformManager:
+		if l.Key == DefaultTerraformVariable {
+			return ""
+		}
+	case envManager:
+		if slices.Contains(defaultEnvKeys, l.Key) {
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	return l.Key
+}
+
+// ScanRequest defines where to look for
+// versions: a local checkout when Dir is
+// set, the Repo repository otherwise.
+type ScanRequest struct {
+	Repo Repository
+	Dir  string
+}
+
+// looksLikeK3sVersion
+//
+// Reports whether a value looks like a pinned k3s
+// version: either a full k3s version, or a semver
+// version held by a key mentioning k3s.
+func looksLikeK3sVersion(key, value string) bool {
+	if k3sVersionPattern.MatchString(value) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(key), "k3s") && semver.IsValid(canonicalVersion(value))
+}
+
+// isScanCandidate
+//
+// Reports whether a file may be handled by a manager,
+// so that other files are not read at all. Ansible
+// variable files may have no extension.
+func isScanCandidate(filePath string) bool {
+	if isEnvFile(filePath) {
+		return true
+	}
+
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".yml", ".yaml", ".tf", ".tfvars", ".tofu":
+		return true
+	case "":
+		return strings.Contains(filePath, "group_vars/") || strings.Contains(filePath, "host_vars/")
+	default:
+		return false
+	}
+}
+
+// Scan
+//
+// Looks for every k3s version pinned in a repository,
+// either through the github API or in a local checkout.
+func (c *ClientSet) Scan(ctx context.Context, req ScanRequest) ([]Location, error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+
+	var (
+		paths []string
+		read  func(filePath string) ([]byte, error)
+		err   error
+	)
+	if req.Dir != "" {
+		logger.Infof("Scanning %q...", req.Dir)
+		paths, err = localFiles(req.Dir)
+		read = func(filePath string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(req.Dir, filepath.FromSlash(filePath)))
+		}
+	} else {
+		logger.Infof("Scanning %s/%s...", req.Repo.Owner, req.Repo.Name)
+		paths, err = c.repositoryFiles(ctx, req.Repo)
+		read = func(filePath string) ([]byte, error) {
+			content, err := c.getGroupVarsFileContent(ctx, UpdateReleaseReq{Repo: req.Repo}.forPath(filePath))
+			return []byte(content), err
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]Location, 0)
+	for _, filePath := range paths {
+		if !isScanCandidate(filePath) {
+			continue
+		}
+
+		content, err := read(filePath)
+		if err != nil {
+			logger.Warnf("Skipping %q: %s", filePath, err)
+			continue
+		}
+
+		name, err := detectManager(filePath, content)
+		if err != nil {
+			continue
+		}
+		for _, pin := range managers[name].scan(filePath, content) {
+			locations = append(locations, Location{
+				Path:    filePath,
+				Manager: name,
+				Key:     pin.key,
+				Version: pin.version,
+			})
+		}
+	}
+
+	return locations, nil
+}
+
+// localFiles
+//
+// Returns the files of a local directory, as slash
+// separated paths relative to the directory.
+func localFiles(dir string) ([]string, error) {
+	paths := make([]string, 0)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && slices.Contains(skippedDirectories, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error when scanning %q: %s", dir, err)
+	}
+
+	return paths, nil
+}
+
+// repositoryFiles
+//
+// Returns the files of a repository, but
+// the ones of skipped directories.
+func (c *ClientSet) repositoryFiles(ctx context.Context, repo Repository) ([]string, error) {
+	files, err := c.listFiles(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(files, func(filePath string) bool {
+		return slices.ContainsFunc(strings.Split(path.Dir(filePath), "/"), func(dir string) bool {
+			return slices.Contains(skippedDirectories, dir)
+		})
+	}), nil
+}
@@ -0,0 +1,187 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	github_mocks "github.com/cguertin14/k3supdater/pkg/github/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v57/github"
+)
+
+// scanFixture is the repository scanned
+// by the tests, by file path.
+var scanFixture = map[string]string{
+	"README.md":                          "INSTALL_K3S_VERSION=v1.28.5+k3s1",
+	"inventory/prod/group_vars/all.yml":  "k3s_release_version: v1.28.5+k3s1\nansible_user: pi\n",
+	"inventory/lab/host_vars/node1":      "k3s:\n  versions:\n    - v1.28.4+k3s1\n",
+	"inventory/lab/group_vars/other.yml": "some_version: v1.2.3\n",
+	"manifests/plans.yml":                "apiVersion: upgrade.cattle.io/v1\nkind: Plan\nmetadata:\n  name: server\nspec:\n  version: v1.28.5+k3s1\n",
+	"images/Dockerfile":                  "FROM alpine\nARG K3S_VERSION=v1.28.5+k3s1\n",
+	"environments/prod.tfvars":           "region = \"ca-central-1\"\ncluster_k3s = \"v1.28.5+k3s1\"\n",
+	"node_modules/some/config.yml":       "k3s_release_version: v1.28.5+k3s1\n",
+}
+
+var expectedScanLocations = []Location{
+	{Path: "environments/prod.tfvars", Manager: terraformManager, Key: "cluster_k3s", Version: "v1.28.5+k3s1"},
+	{Path: "images/Dockerfile", Manager: envManager, Key: "K3S_VERSION", Version: "v1.28.5+k3s1"},
+	{Path: "inventory/lab/host_vars/node1", Manager: ansibleManager, Key: "k3s.versions[0]", Version: "v1.28.4+k3s1"},
+	{Path: "inventory/prod/group_vars/all.yml", Manager: ansibleManager, Key: "k3s_release_version", Version: "v1.28.5+k3s1"},
+	{Path: "manifests/plans.yml", Manager: planManager, Key: "spec.version", Version: "v1.28.5+k3s1"},
+}
+
+func TestScanDirectory(t *testing.T) {
+	dir := t.TempDir()
+	for filePath, content := range scanFixture {
+		fullPath := filepath.Join(dir, filepath.FromSlash(filePath))
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := NewClient(context.Background(), Dependencies{
+		Client: github_mocks.NewMockClient(gomock.NewController(t)),
+	})
+
+	locations, err := client.Scan(context.Background(), ScanRequest{Dir: dir})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(locations, expectedScanLocations) {
+		t.Fatalf("expected %v, got %v", expectedScanLocations, locations)
+	}
+}
+
+func TestScanRepository(t *testing.T) {
+	cases := map[string]struct {
+		listError error
+		readError error
+
+		expectedLocations []Location
+		expectError       bool
+	}{
+		"success case with no error": {
+			expectedLocations: expectedScanLocations,
+		},
+		"success case with unreadable files": {
+			readError:         errors.New("some error"),
+			expectedLocations: []Location{},
+		},
+		"error case with listing error": {
+			listError:   errors.New("some error"),
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// create new mock client instance
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+
+			// define mock behavior
+			entries := make([]*github.TreeEntry, 0)
+			for filePath := range scanFixture {
+				entries = append(entries, &github.TreeEntry{Path: github.String(filePath), Type: github.String("blob")})
+			}
+			sort.Slice(entries, func(i, j int) bool {
+				return entries[i].GetPath() < entries[j].GetPath()
+			})
+			githubMockClient.EXPECT().GetTree(gomock.Any(), legacy.GetTreeRequest{
+				Owner:     "some owner",
+				Repo:      "some name",
+				SHA:       "main",
+				Recursive: true,
+			}).
+				Times(1).
+				Return(&github.Tree{Entries: entries}, nil, c.listError)
+			githubMockClient.EXPECT().GetRepositoryContents(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				DoAndReturn(func(_ context.Context, req legacy.GetRepositoryContentsRequest) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+					if req.Branch != "main" {
+						t.Errorf("unexpected branch %q", req.Branch)
+					}
+					return &github.RepositoryContent{
+						Content: github.String(base64.StdEncoding.EncodeToString([]byte(scanFixture[req.Path]))),
+					}, nil, nil, c.readError
+				})
+
+			// create mock updater client
+			client := NewClient(context.Background(), Dependencies{
+				Client: githubMockClient,
+			})
+
+			locations, err := client.Scan(context.Background(), ScanRequest{
+				Repo: Repository{
+					Owner:  "some owner",
+					Name:   "some name",
+					Branch: "main",
+				},
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(locations, c.expectedLocations) {
+				t.Fatalf("expected %v, got %v", c.expectedLocations, locations)
+			}
+		})
+	}
+}
+
+func TestLocationVersionKey(t *testing.T) {
+	cases := map[string]struct {
+		location Location
+
+		expected string
+	}{
+		"ansible default key": {
+			location: Location{Manager: ansibleManager, Key: DefaultVersionKey},
+		},
+		"ansible custom key": {
+			location: Location{Manager: ansibleManager, Key: "k3s.version"},
+			expected: "k3s.version",
+		},
+		"terraform default variable": {
+			location: Location{Manager: terraformManager, Key: DefaultTerraformVariable},
+		},
+		"env default key": {
+			location: Location{Manager: envManager, Key: "INSTALL_K3S_VERSION"},
+		},
+		"env custom key": {
+			location: Location{Manager: envManager, Key: "K3S_RELEASE"},
+			expected: "K3S_RELEASE",
+		},
+		"plan": {
+			location: Location{Manager: planManager, Key: "spec.version"},
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if key := c.location.VersionKey(); key != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, key)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package updater
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/cguertin14/logger"
+)
+
+// templateExpression matches the expressions of a
+// jinja template, i.e.: "v{{ k3s_kube_version }}".
+var templateExpression = regexp.MustCompile(`\{\{-?\s*(.*?)\s*-?\}\}`)
+
+// templateVariableName matches the expressions
+// which are plain variable references, the
+// only ones that can be resolved.
+var templateVariableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// maxTemplateDepth is how deep templates can
+// reference variables holding other templates.
+const maxTemplateDepth int = 10
+
+// isTemplate
+//
+// Reports whether a value is a jinja template.
+func isTemplate(value string) bool {
+	return strings.Contains(value, "{{")
+}
+
+// templatePart is either a literal
+// or a variable of a template.
+type templatePart struct {
+	literal  string
+	variable string
+}
+
+// templateVariable is a variable referenced by
+// a template, along with every file defining it
+// and their values. The first one is used.
+type templateVariable struct {
+	value       string
+	definitions []*versionFile
+	values      []string
+}
+
+// versionTemplate is a version written as a jinja
+// template, i.e.: "v{{ k3s_kube_version }}+k3s{{ k3s_rev }}".
+// Variables holding templates themselves are inlined,
+// so that parts only reference plain values.
+type versionTemplate struct {
+	raw       string
+	parts     []templatePart
+	variables map[string]*templateVariable
+}
+
+// parseTemplate
+//
+// Splits a template into its parts. Only plain
+// variable references are supported, and two of
+// them can't follow each other, since the values
+// couldn't be told apart when updating them.
+func parseTemplate(value string) ([]templatePart, error) {
+	parts := make([]templatePart, 0)
+	last := 0
+	for _, match := range templateExpression.FindAllStringSubmatchIndex(value, -1) {
+		if match[0] > last {
+			parts = append(parts, templatePart{literal: value[last:match[0]]})
+		}
+
+		name := value[match[2]:match[3]]
+		if !templateVariableName.MatchString(name) {
+			return nil, fmt.Errorf("unsupported template expression %q in %q, only variable references are", value[match[0]:match[1]], value)
+		}
+		if len(parts) > 0 && parts[len(parts)-1].variable != "" {
+			return nil, fmt.Errorf("unsupported template %q, variables must be separated", value)
+		}
+
+		parts = append(parts, templatePart{variable: name})
+		last = match[1]
+	}
+	if last < len(value) {
+		parts = append(parts, templatePart{literal: value[last:]})
+	}
+
+	return parts, nil
+}
+
+// render
+//
+// Returns the version the template evaluates to.
+func (t *versionTemplate) render() string {
+	var b strings.Builder
+	for _, part := range t.parts {
+		if part.variable != "" {
+			b.WriteString(t.variables[part.variable].value)
+			continue
+		}
+		b.WriteString(part.literal)
+	}
+	return b.String()
+}
+
+// solve
+//
+// Returns the values the variables of the
+// template must hold for it to evaluate to
+// a version.
+func (t *versionTemplate) solve(version string) (map[string]string, error) {
+	pattern := make([]string, 0, len(t.parts))
+	names := make([]string, 0)
+	for _, part := range t.parts {
+		if part.variable != "" {
+			pattern = append(pattern, "(.+?)")
+			names = append(names, part.variable)
+			continue
+		}
+		pattern = append(pattern, regexp.QuoteMeta(part.literal))
+	}
+
+	match := regexp.MustCompile("^" + strings.Join(pattern, "") + "$").FindStringSubmatch(version)
+	if match == nil {
+		return nil, fmt.Errorf("version %q doesn't match the template %q", version, t.raw)
+	}
+
+	values := make(map[string]string)
+	for i, name := range names {
+		if value, ok := values[name]; ok && value != match[i+1] {
+			return nil, fmt.Errorf("version %q doesn't match the template %q: %q would have several values", version, t.raw, name)
+		}
+		values[name] = match[i+1]
+	}
+
+	return values, nil
+}
+
+// templateManager updates a version written as a
+// template, through the variables it references.
+// Only the ones defined in the file itself are
+// updated by the manager, see templateFiles.
+type templateManager struct {
+	template *versionTemplate
+}
+
+func (m templateManager) extract(_ UpdateReleaseReq, _ []byte) (string, error) {
+	return m.template.render(), nil
+}
+
+func (m templateManager) update(req updateFileReq) ([]byte, error) {
+	values, err := m.template.solve(*req.latestRelease.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	content := []byte(req.fileContent)
+	for _, name := range m.template.names() {
+		for _, d := range m.template.variables[name].definitions {
+			if d.path != req.Repo.Path {
+				continue
+			}
+			if content, err = setYAMLScalar(content, []string{name}, values[name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return updateChecksum(req, content)
+}
+
+func (m templateManager) scan(filePath string, content []byte) []versionPin {
+	return managers[ansibleManager].scan(filePath, content)
+}
+
+// names
+//
+// Returns the variables of the template, sorted.
+func (t *versionTemplate) names() []string {
+	names := make([]string, 0, len(t.variables))
+	for name := range t.variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveTemplate
+//
+// Resolves the version of an ansible file when it is
+// written as a template. Variables are looked up in the
+// file itself, then in the group_vars and host_vars of
+// its inventory, where every definition is updated.
+func (c *ClientSet) resolveTemplate(ctx context.Context, req UpdateReleaseReq, f *versionFile, cache inventoryCache) error {
+	raw, err := managers[ansibleManager].extract(req, []byte(f.content))
+	if err != nil || !isTemplate(raw) {
+		// Errors are reported by the manager
+		return nil
+	}
+
+	t := &versionTemplate{
+		raw:       raw,
+		variables: make(map[string]*templateVariable),
+	}
+	if t.parts, err = c.inlineTemplate(ctx, req, f, raw, t.variables, cache, 0); err != nil {
+		return fmt.Errorf("error when resolving the version of %q: %s", f.path, err)
+	}
+
+	f.manager = templateManager{template: t}
+	return nil
+}
+
+// inlineTemplate
+//
+// Returns the parts of a template, where variables
+// holding templates are replaced by their own parts.
+func (c *ClientSet) inlineTemplate(ctx context.Context, req UpdateReleaseReq, f *versionFile, value string, variables map[string]*templateVariable, cache inventoryCache, depth int) ([]templatePart, error) {
+	logger := logger.NewFromContextOrDefault(ctx)
+	if depth >= maxTemplateDepth {
+		return nil, fmt.Errorf("template %q is nested too deeply, or references itself", value)
+	}
+
+	parts, err := parseTemplate(value)
+	if err != nil {
+		return nil, err
+	}
+
+	inlined := make([]templatePart, 0, len(parts))
+	for _, part := range parts {
+		if part.variable == "" {
+			inlined = append(inlined, part)
+			continue
+		}
+
+		definitions, values, err := c.lookupVariable(ctx, req, f, part.variable, cache)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(values, func(v string) bool { return v != values[0] }) {
+			logger.Warnf("%q is defined with different values, %q from %q is used.", part.variable, values[0], definitions[0].path)
+		}
+
+		if !isTemplate(values[0]) {
+			if _, ok := variables[part.variable]; !ok {
+				variables[part.variable] = &templateVariable{value: values[0], definitions: definitions, values: values}
+			}
+			inlined = append(inlined, part)
+			continue
+		}
+
+		nested, err := c.inlineTemplate(ctx, req, definitions[0], values[0], variables, cache, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		inlined = append(inlined, nested...)
+	}
+
+	// Inlined templates may join variables
+	for i := 1; i < len(inlined); i++ {
+		if inlined[i-1].variable != "" && inlined[i].variable != "" {
+			return nil, fmt.Errorf("unsupported template %q, variables must be separated", value)
+		}
+	}
+
+	return inlined, nil
+}
+
+// lookupVariable
+//
+// Returns the files defining a variable referenced by
+// a template, along with their values. The file of the
+// template comes first, then the other files of its
+// inventory by precedence.
+func (c *ClientSet) lookupVariable(ctx context.Context, req UpdateReleaseReq, f *versionFile, name string, cache inventoryCache) ([]*versionFile, []string, error) {
+	candidates := []*versionFile{f}
+	if inventory, ok := inventoryOf(f.path); ok {
+		files, err := c.inventoryFiles(ctx, req, inventory, cache)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range files {
+			if file.path != f.path {
+				candidates = append(candidates, file)
+			}
+		}
+	}
+
+	definitions, values := make([]*versionFile, 0), make([]string, 0)
+	for _, candidate := range candidates {
+		scalar, err := findYAMLScalar([]byte(candidate.content), []string{name})
+		if err != nil {
+			continue
+		}
+		definitions = append(definitions, candidate)
+		values = append(values, scalar.node.Value)
+	}
+	if len(definitions) == 0 {
+		return nil, nil, fmt.Errorf("variable %q is not defined in the inventory", name)
+	}
+
+	return definitions, values, nil
+}
+
+// templateFiles
+//
+// Returns the files to commit once the updated files are
+// updated, which include the other files defining the
+// variables of templates, and a note listing the updated
+// variables. Files left unchanged are not committed.
+func templateFiles(updates []*versionFile) ([]*versionFile, []string, error) {
+	files, notes := slices.Clone(updates), make([]string, 0)
+	byPath := make(map[string]*versionFile)
+	for _, f := range files {
+		byPath[f.path] = f
+	}
+
+	for _, f := range updates {
+		m, ok := f.manager.(templateManager)
+		if !ok {
+			continue
+		}
+
+		values, err := m.template.solve(*f.latestRelease.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		notes = append(notes, templateNote(f, values))
+
+		for _, name := range m.template.names() {
+			for _, d := range m.template.variables[name].definitions {
+				if d.path == f.path {
+					continue
+				}
+
+				target, ok := byPath[d.path]
+				if !ok {
+					target = &versionFile{
+						path:           d.path,
+						content:        d.content,
+						currentVersion: f.currentVersion,
+						manager:        d.manager,
+						vault:          d.vault,
+						updatedContent: []byte(d.content),
+						latestRelease:  f.latestRelease,
+					}
+					byPath[d.path] = target
+					files = append(files, target)
+				}
+
+				if target.updatedContent, err = setYAMLScalar(target.updatedContent, []string{name}, values[name]); err != nil {
+					return nil, nil, fmt.Errorf("error when updating %q in %q: %s", name, d.path, err)
+				}
+			}
+		}
+	}
+
+	return slices.DeleteFunc(files, func(f *versionFile) bool {
+		return string(f.updatedContent) == f.content
+	}), notes, nil
+}
+
+// templateNote
+//
+// Lists the variables updated for a
+// templated version, in a markdown table.
+func templateNote(f *versionFile, values map[string]string) string {
+	t := f.manager.(templateManager).template
+	lines := []string{
+		fmt.Sprintf("**The version of `%s` is the `%s` template**, updated through its variables:", f.path, t.raw),
+		"",
+		"| Variable | File | Current value | New value |",
+		"| --- | --- | --- | --- |",
+	}
+	for _, name := range t.names() {
+		for i, d := range t.variables[name].definitions {
+			lines = append(lines, fmt.Sprintf("| `%s` | `%s` | `%s` | `%s` |", name, d.path, t.variables[name].values[i], values[name]))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,359 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	github_mocks "github.com/cguertin14/k3supdater/pkg/github/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v57/github"
+)
+
+func TestParseTemplate(t *testing.T) {
+	cases := map[string]struct {
+		value string
+
+		expected    []templatePart
+		expectError bool
+	}{
+		"success case with variables": {
+			value: "v{{ k3s_kube_version }}+k3s{{k3s_rev}}",
+			expected: []templatePart{
+				{literal: "v"},
+				{variable: "k3s_kube_version"},
+				{literal: "+k3s"},
+				{variable: "k3s_rev"},
+			},
+		},
+		"success case with whitespace control": {
+			value:    "{{- k3s_version -}}",
+			expected: []templatePart{{variable: "k3s_version"}},
+		},
+		"error case with filter": {
+			value:       "v{{ k3s_kube_version | default('1.30.4') }}+k3s1",
+			expectError: true,
+		},
+		"error case with adjacent variables": {
+			value:       "v{{ k3s_minor }}{{ k3s_patch }}+k3s1",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			parts, err := parseTemplate(c.value)
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(parts, c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, parts)
+			}
+		})
+	}
+}
+
+func TestVersionTemplateSolve(t *testing.T) {
+	cases := map[string]struct {
+		parts   []templatePart
+		version string
+
+		expected    map[string]string
+		expectError bool
+	}{
+		"success case with no error": {
+			parts: []templatePart{
+				{literal: "v"},
+				{variable: "k3s_kube_version"},
+				{literal: "+k3s"},
+				{variable: "k3s_rev"},
+			},
+			version:  "v1.30.4+k3s2",
+			expected: map[string]string{"k3s_kube_version": "1.30.4", "k3s_rev": "2"},
+		},
+		"success case with repeated variable": {
+			parts: []templatePart{
+				{literal: "v1."},
+				{variable: "k3s_minor"},
+				{literal: ".4+k3s1-"},
+				{variable: "k3s_minor"},
+			},
+			version:  "v1.30.4+k3s1-30",
+			expected: map[string]string{"k3s_minor": "30"},
+		},
+		"error case with other format": {
+			parts: []templatePart{
+				{literal: "v"},
+				{variable: "k3s_kube_version"},
+				{literal: "+k3s1"},
+			},
+			version:     "v1.30.4+k3s2",
+			expectError: true,
+		},
+		"error case with inconsistent repeated variable": {
+			parts: []templatePart{
+				{literal: "v1."},
+				{variable: "k3s_minor"},
+				{literal: ".4+k3s1-"},
+				{variable: "k3s_minor"},
+			},
+			version:     "v1.30.4+k3s1-29",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			values, err := (&versionTemplate{parts: c.parts}).solve(c.version)
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !reflect.DeepEqual(values, c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, values)
+			}
+		})
+	}
+}
+
+func TestUpdateK3sReleaseTemplate(t *testing.T) {
+	const template = "k3s_release_version: \"v{{ k3s_kube_version }}+k3s{{ k3s_rev }}\"\n"
+
+	cases := map[string]struct {
+		files map[string]string
+
+		expectedUpdates map[string]string
+		expectedTitle   string
+		expectError     bool
+
+		expectedKubeVersionFile string
+	}{
+		"success case with variables in the same file": {
+			files: map[string]string{
+				"inventory/prod/group_vars/all.yml": template + "k3s_kube_version: 1.28.5\nk3s_rev: 1\n",
+			},
+			expectedUpdates: map[string]string{
+				"inventory/prod/group_vars/all.yml": template + "k3s_kube_version: 1.29.6\nk3s_rev: 1\n",
+			},
+			expectedKubeVersionFile: "inventory/prod/group_vars/all.yml",
+			expectedTitle:           "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		},
+		"success case with variables in other files": {
+			files: map[string]string{
+				"inventory/prod/group_vars/all.yml": template + "k3s_rev: 1\n",
+				"inventory/prod/group_vars/k3s.yml": "k3s_kube_version: \"1.28.5\"\n",
+				"inventory/lab/group_vars/k3s.yml":  "k3s_kube_version: \"1.27.0\"\n",
+			},
+			expectedKubeVersionFile: "inventory/prod/group_vars/k3s.yml",
+			expectedUpdates: map[string]string{
+				"inventory/prod/group_vars/k3s.yml": "k3s_kube_version: \"1.29.6\"\n",
+			},
+			expectedTitle: "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		},
+		"success case with nested template": {
+			files: map[string]string{
+				"inventory/prod/group_vars/all.yml": "k3s_release_version: \"{{ k3s_version }}\"\n",
+				"inventory/prod/group_vars/k3s.yml": "k3s_version: \"v{{ k3s_kube_version }}+k3s1\"\nk3s_kube_version: \"1.28.5\"\n",
+			},
+			expectedKubeVersionFile: "inventory/prod/group_vars/k3s.yml",
+			expectedUpdates: map[string]string{
+				"inventory/prod/group_vars/k3s.yml": "k3s_version: \"v{{ k3s_kube_version }}+k3s1\"\nk3s_kube_version: \"1.29.6\"\n",
+			},
+			expectedTitle: "new release: k3s update from v1.28.5+k3s1 to v1.29.6+k3s1",
+		},
+		"error case with undefined variable": {
+			files: map[string]string{
+				"inventory/prod/group_vars/all.yml": template + "k3s_rev: 1\n",
+			},
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// create new mock client instance
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+
+			// define mock behavior
+			entries := make([]*github.TreeEntry, 0)
+			for filePath := range c.files {
+				entries = append(entries, &github.TreeEntry{Path: github.String(filePath), Type: github.String("blob")})
+			}
+			sort.Slice(entries, func(i, j int) bool {
+				return entries[i].GetPath() < entries[j].GetPath()
+			})
+			githubMockClient.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(&github.Tree{Entries: entries}, nil, nil)
+			githubMockClient.EXPECT().GetRepositoryContents(gomock.Any(), gomock.Any()).
+				AnyTimes().
+				DoAndReturn(func(_ context.Context, req legacy.GetRepositoryContentsRequest) (*github.RepositoryContent, []*github.RepositoryContent, *github.Response, error) {
+					return &github.RepositoryContent{
+						Content: github.String(base64.StdEncoding.EncodeToString([]byte(c.files[req.Path]))),
+					}, nil, nil, nil
+				})
+
+			updated := make(map[string]string)
+			var pr *github.NewPullRequest
+			if !c.expectError {
+				githubMockClient.EXPECT().GetRepositoryReleases(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*github.RepositoryRelease{
+						{Name: github.String("v1.29.6+k3s1"), Body: github.String("some release notes")},
+						{Name: github.String("v1.28.5+k3s1"), Body: github.String("some release notes")},
+					}, nil, nil)
+				githubMockClient.EXPECT().GetBranch(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(&github.Reference{
+						Object: &github.GitObject{},
+					}, nil, nil)
+				githubMockClient.EXPECT().CreateBranch(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, nil, nil)
+				githubMockClient.EXPECT().GetCommit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Commit{Tree: &github.Tree{}}, nil, nil)
+				// the base tree, for the modes of the committed files
+				githubMockClient.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Tree{}, nil, nil)
+				githubMockClient.EXPECT().CreateBlob(gomock.Any(), gomock.Any()).
+					Times(len(c.expectedUpdates)).
+					DoAndReturn(func(_ context.Context, req legacy.CreateBlobRequest) (*github.Blob, *github.Response, error) {
+						content, _ := base64.StdEncoding.DecodeString(req.GetContent())
+						return &github.Blob{SHA: github.String(string(content))}, nil, nil
+					})
+				githubMockClient.EXPECT().CreateTree(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, req legacy.CreateTreeRequest) (*github.Tree, *github.Response, error) {
+						for _, entry := range req.Entries {
+							updated[entry.GetPath()] = entry.GetSHA()
+						}
+						return &github.Tree{}, nil, nil
+					})
+				githubMockClient.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Commit{}, nil, nil)
+				githubMockClient.EXPECT().UpdateRef(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, nil, nil)
+				githubMockClient.EXPECT().CreatePullRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, req legacy.CreatePRRequest) (*github.PullRequest, *github.Response, error) {
+						pr = req.NewPullRequest
+						return nil, nil, nil
+					})
+			}
+
+			// create mock updater client
+			client := NewClient(context.Background(), Dependencies{
+				Client: githubMockClient,
+			})
+
+			err := client.UpdateK3sRelease(context.Background(), UpdateReleaseReq{
+				Repo: Repository{
+					Owner:  "some owner",
+					Name:   "some name",
+					Path:   "inventory/prod/group_vars/all.yml",
+					Branch: "main",
+				},
+				ReleaseRepo: Repository{
+					Owner: "k3s-io",
+					Name:  "k3s",
+				},
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(updated, c.expectedUpdates) {
+				t.Fatalf("expected updates %v, got %v", c.expectedUpdates, updated)
+			}
+			if pr.GetTitle() != c.expectedTitle {
+				t.Fatalf("expected title %q, got %q", c.expectedTitle, pr.GetTitle())
+			}
+			row := "| `k3s_kube_version` | `" + c.expectedKubeVersionFile + "` | `1.28.5` | `1.29.6` |"
+			if !strings.Contains(pr.GetBody(), row) {
+				t.Fatalf("expected body to contain %q, got %q", row, pr.GetBody())
+			}
+		})
+	}
+}
+
+func TestTemplateManagerUpdateChecksum(t *testing.T) {
+	const template = "k3s_release_version: \"v{{ k3s_kube_version }}+k3s1\"\n"
+
+	cases := map[string]struct {
+		fileContent string
+
+		expected string
+	}{
+		"success case with checksum key": {
+			fileContent: template + "k3s_kube_version: 1.28.5\nk3s_checksum: abc\n",
+			expected:    template + "k3s_kube_version: 1.29.6\nk3s_checksum: def\n",
+		},
+		"success case with missing checksum key": {
+			fileContent: template + "k3s_kube_version: 1.28.5\n",
+			expected:    template + "k3s_kube_version: 1.29.6\n",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			manager := templateManager{template: &versionTemplate{
+				raw:   "v{{ k3s_kube_version }}+k3s1",
+				parts: []templatePart{{literal: "v"}, {variable: "k3s_kube_version"}, {literal: "+k3s1"}},
+				variables: map[string]*templateVariable{
+					"k3s_kube_version": {
+						value:       "1.28.5",
+						definitions: []*versionFile{{path: "inventory/prod/group_vars/all.yml"}},
+					},
+				},
+			}}
+
+			content, err := manager.update(updateFileReq{
+				fileContent:   c.fileContent,
+				latestRelease: &github.RepositoryRelease{Name: github.String("v1.29.6+k3s1")},
+				checksums:     []assetChecksum{{arch: "amd64", asset: "k3s", sha256: "def"}},
+				UpdateReleaseReq: UpdateReleaseReq{
+					Repo:            Repository{Path: "inventory/prod/group_vars/all.yml"},
+					VerifyChecksums: true,
+					ChecksumKey:     "k3s_checksum",
+				},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(content) != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, content)
+			}
+		})
+	}
+}
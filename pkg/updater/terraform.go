@@ -0,0 +1,183 @@
+package updater
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DefaultTerraformVariable is the variable holding
+// the version in terraform files, unless a version
+// key is configured.
+const DefaultTerraformVariable string = "k3s_version"
+
+// terraformFileManager updates the version of
+// terraform (or OpenTofu) files, either in the
+// default of a variable block, a locals block,
+// or an assignment of a .tfvars file.
+type terraformFileManager struct{}
+
+// terraformVariable
+//
+// Returns the name of the variable
+// holding the version.
+func terraformVariable(req UpdateReleaseReq) string {
+	if req.VersionKey == "" {
+		return DefaultTerraformVariable
+	}
+	return req.VersionKey
+}
+
+// terraformAttributes
+//
+// Returns the bodies and names of the attributes
+// defining a variable, which are:
+//
+//	variable "k3s_version" { default = "..." }
+//	locals { k3s_version = "..." }
+//	k3s_version = "..." (.tfvars files only)
+func terraformAttributes(file *hclwrite.File, filePath, name string) (bodies []*hclwrite.Body, attributes []string) {
+	if strings.HasSuffix(filePath, ".tfvars") {
+		if file.Body().GetAttribute(name) != nil {
+			bodies, attributes = append(bodies, file.Body()), append(attributes, name)
+		}
+		return
+	}
+
+	for _, block := range file.Body().Blocks() {
+		switch {
+		case block.Type() == "variable" && len(block.Labels()) == 1 && block.Labels()[0] == name:
+			if block.Body().GetAttribute("default") != nil {
+				bodies, attributes = append(bodies, block.Body()), append(attributes, "default")
+			}
+		case block.Type() == "locals":
+			if block.Body().GetAttribute(name) != nil {
+				bodies, attributes = append(bodies, block.Body()), append(attributes, name)
+			}
+		}
+	}
+
+	return
+}
+
+// parseTerraformFile
+//
+// Parses a terraform file and returns the
+// attributes defining the version variable.
+func parseTerraformFile(req UpdateReleaseReq, content []byte) (*hclwrite.File, []*hclwrite.Body, []string, error) {
+	file, diags := hclwrite.ParseConfig(content, path.Base(req.Repo.Path), hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, nil, nil, fmt.Errorf("error when parsing hcl: %s", diags.Error())
+	}
+
+	name := terraformVariable(req)
+	bodies, attributes := terraformAttributes(file, req.Repo.Path, name)
+	if len(bodies) == 0 {
+		return nil, nil, nil, fmt.Errorf("%q variable not found", name)
+	}
+
+	return file, bodies, attributes, nil
+}
+
+// terraformString
+//
+// Returns the value of an attribute, which
+// must be a plain string with no template.
+func terraformString(attribute *hclwrite.Attribute) (string, error) {
+	src := attribute.Expr().BuildTokens(nil).Bytes()
+	expr, diags := hclsyntax.ParseExpression(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", fmt.Errorf("error when parsing hcl: %s", diags.Error())
+	}
+
+	template, ok := expr.(*hclsyntax.TemplateExpr)
+	if !ok || !template.IsStringLiteral() {
+		return "", fmt.Errorf("%q is not a plain string", strings.TrimSpace(string(src)))
+	}
+
+	value, diags := template.Value(nil)
+	if diags.HasErrors() {
+		return "", fmt.Errorf("error when reading %q: %s", strings.TrimSpace(string(src)), diags.Error())
+	}
+
+	return value.AsString(), nil
+}
+
+func (terraformFileManager) extract(req UpdateReleaseReq, content []byte) (string, error) {
+	_, bodies, attributes, err := parseTerraformFile(req, content)
+	if err != nil {
+		return "", err
+	}
+
+	versions := make([]string, 0, len(bodies))
+	for i, body := range bodies {
+		version, err := terraformString(body.GetAttribute(attributes[i]))
+		if err != nil {
+			return "", err
+		}
+		versions = append(versions, version)
+	}
+
+	return oldestK3sVersion(versions), nil
+}
+
+func (terraformFileManager) update(req updateFileReq) ([]byte, error) {
+	file, bodies, attributes, err := parseTerraformFile(req.UpdateReleaseReq, []byte(req.fileContent))
+	if err != nil {
+		return nil, err
+	}
+
+	for i, body := range bodies {
+		body.SetAttributeValue(attributes[i], cty.StringVal(*req.latestRelease.Name))
+	}
+
+	return file.Bytes(), nil
+}
+
+func (terraformFileManager) scan(filePath string, content []byte) []versionPin {
+	file, diags := hclwrite.ParseConfig(content, path.Base(filePath), hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+
+	// Every variable is looked at, so that
+	// custom names are found as well.
+	names := make([]string, 0)
+	if strings.HasSuffix(filePath, ".tfvars") {
+		for name := range file.Body().Attributes() {
+			names = append(names, name)
+		}
+	} else {
+		for _, block := range file.Body().Blocks() {
+			switch {
+			case block.Type() == "variable" && len(block.Labels()) == 1:
+				names = append(names, block.Labels()[0])
+			case block.Type() == "locals":
+				for name := range block.Body().Attributes() {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+
+	pins := make([]versionPin, 0)
+	for _, name := range slices.Compact(names) {
+		bodies, attributes := terraformAttributes(file, filePath, name)
+		for i, body := range bodies {
+			version, err := terraformString(body.GetAttribute(attributes[i]))
+			if err == nil && looksLikeK3sVersion(name, version) {
+				pins = append(pins, versionPin{key: name, version: version})
+			}
+		}
+	}
+
+	return pins
+}
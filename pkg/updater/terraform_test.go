@@ -0,0 +1,147 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v57/github"
+)
+
+func TestTerraformFileManager(t *testing.T) {
+	cases := map[string]struct {
+		path       string
+		versionKey string
+		content    string
+
+		expectedVersion string
+		expectedContent string
+		expectError     bool
+	}{
+		"success case with a variable default": {
+			path: "modules/k3s/variables.tf",
+			content: `# The k3s version of every node
+variable "k3s_version" {
+  type        = string
+  description = "k3s version"
+  default     = "v1.28.5+k3s1" # pinned by k3supdater
+}
+
+variable "k3s_token" {
+  type      = string
+  sensitive = true
+}
+`,
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: `# The k3s version of every node
+variable "k3s_version" {
+  type        = string
+  description = "k3s version"
+  default     = "v1.29.6+k3s2" # pinned by k3supdater
+}
+
+variable "k3s_token" {
+  type      = string
+  sensitive = true
+}
+`,
+		},
+		"success case with a tfvars assignment": {
+			path: "environments/prod.tfvars",
+			content: `region      = "ca-central-1"
+k3s_version = "v1.28.5+k3s1"
+node_count  = 3
+`,
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: `region      = "ca-central-1"
+k3s_version = "v1.29.6+k3s2"
+node_count  = 3
+`,
+		},
+		"success case with locals and a version key": {
+			path:       "main.tf",
+			versionKey: "install_k3s_version",
+			content: `locals {
+  install_k3s_version = "v1.28.5+k3s1"
+}
+
+module "cluster" {
+  source      = "./modules/k3s"
+  k3s_version = local.install_k3s_version
+}
+`,
+			expectedVersion: "v1.28.5+k3s1",
+			expectedContent: `locals {
+  install_k3s_version = "v1.29.6+k3s2"
+}
+
+module "cluster" {
+  source      = "./modules/k3s"
+  k3s_version = local.install_k3s_version
+}
+`,
+		},
+		"error case with a template": {
+			path: "main.tf",
+			content: `locals {
+  k3s_version = "v${var.kube_version}+k3s1"
+}
+`,
+			expectError: true,
+		},
+		"error case with a tfvars assignment in a tf file": {
+			path:        "main.tf",
+			content:     `k3s_version = "v1.28.5+k3s1"`,
+			expectError: true,
+		},
+		"error case with missing variable": {
+			path:        "variables.tf",
+			content:     `variable "k3s_version" {}`,
+			expectError: true,
+		},
+		"error case with invalid hcl": {
+			path:        "variables.tf",
+			content:     `variable "k3s_version" {`,
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			m := terraformFileManager{}
+			req := UpdateReleaseReq{
+				Repo:       Repository{Path: c.path},
+				VersionKey: c.versionKey,
+			}
+
+			version, err := m.extract(req, []byte(c.content))
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if version != c.expectedVersion {
+				t.Fatalf("expected version %q, got %q", c.expectedVersion, version)
+			}
+
+			updated, err := m.update(updateFileReq{
+				fileContent: c.content,
+				latestRelease: &github.RepositoryRelease{
+					Name: github.String("v1.29.6+k3s2"),
+				},
+				UpdateReleaseReq: req,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if string(updated) != c.expectedContent {
+				t.Fatalf("expected:\n%s\ngot:\n%s", c.expectedContent, updated)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package updater
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// vaultPrefix starts every file
+	// encrypted with ansible-vault.
+	vaultPrefix string = "$ANSIBLE_VAULT"
+
+	// vaultCipher is the only cipher
+	// ansible-vault encrypts with.
+	vaultCipher string = "AES256"
+
+	// Key derivation settings of the AES256 format,
+	// which derives the AES key, the HMAC key and
+	// the counter IV from the password at once.
+	vaultIterations int = 10000
+	vaultKeyLength  int = 32
+	vaultIVLength   int = 16
+	vaultSaltLength int = 32
+
+	// vaultLineLength is the length of the
+	// hex lines of an encrypted file.
+	vaultLineLength int = 80
+)
+
+// errNoVaultPassword is returned when reading
+// an encrypted file with no vault password set.
+var errNoVaultPassword = errors.New("no vault password set")
+
+// vaultEnvelope is the header of a file encrypted
+// with ansible-vault, i.e.:
+//
+//	$ANSIBLE_VAULT;1.1;AES256
+//	$ANSIBLE_VAULT;1.2;AES256;prod
+type vaultEnvelope struct {
+	version string
+	label   string
+}
+
+// isVaultFile
+//
+// Reports whether a file is
+// encrypted with ansible-vault.
+func isVaultFile(content []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(content), []byte(vaultPrefix+";"))
+}
+
+// parseVaultEnvelope
+//
+// Splits an encrypted file into its
+// header and its hex encoded payload.
+func parseVaultEnvelope(content []byte) (*vaultEnvelope, []byte, error) {
+	header, payload, _ := bytes.Cut(bytes.TrimSpace(content), []byte("\n"))
+	fields := strings.Split(strings.TrimSpace(string(header)), ";")
+	if len(fields) < 3 || fields[0] != vaultPrefix {
+		return nil, nil, fmt.Errorf("invalid vault header %q", header)
+	}
+	if fields[2] != vaultCipher {
+		return nil, nil, fmt.Errorf("unsupported vault cipher %q", fields[2])
+	}
+
+	envelope := &vaultEnvelope{version: fields[1]}
+	switch {
+	case envelope.version == "1.2" && len(fields) == 4:
+		envelope.label = fields[3]
+	case envelope.version != "1.1" || len(fields) != 3:
+		return nil, nil, fmt.Errorf("invalid vault header %q", header)
+	}
+
+	// The payload is wrapped over several lines
+	payload = bytes.Join(bytes.Fields(payload), nil)
+	decoded := make([]byte, hex.DecodedLen(len(payload)))
+	if _, err := hex.Decode(decoded, payload); err != nil {
+		return nil, nil, fmt.Errorf("error when decoding vault payload: %s", err)
+	}
+
+	return envelope, decoded, nil
+}
+
+// deriveVaultKeys
+//
+// Derives the AES key, the HMAC key and
+// the counter IV of a file from its salt.
+func deriveVaultKeys(password, salt []byte) (cipherKey, hmacKey, iv []byte) {
+	key := pbkdf2.Key(password, salt, vaultIterations, 2*vaultKeyLength+vaultIVLength, sha256.New)
+	return key[:vaultKeyLength], key[vaultKeyLength : 2*vaultKeyLength], key[2*vaultKeyLength:]
+}
+
+// decryptVault
+//
+// Decrypts a file encrypted with ansible-vault,
+// once its HMAC is checked against the password.
+func decryptVault(content, password []byte) (*vaultEnvelope, []byte, error) {
+	envelope, payload, err := parseVaultEnvelope(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The payload holds the hex encoded salt,
+	// HMAC and ciphertext, on separate lines.
+	parts := bytes.Split(payload, []byte("\n"))
+	if len(parts) != 3 {
+		return nil, nil, errors.New("invalid vault payload")
+	}
+	decoded := make([][]byte, len(parts))
+	for i, part := range parts {
+		if decoded[i], err = hex.DecodeString(string(part)); err != nil {
+			return nil, nil, fmt.Errorf("error when decoding vault payload: %s", err)
+		}
+	}
+	salt, sum, ciphertext := decoded[0], decoded[1], decoded[2]
+
+	cipherKey, hmacKey, iv := deriveVaultKeys(password, salt)
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(ciphertext)
+	if !hmac.Equal(mac.Sum(nil), sum) {
+		return nil, nil, errors.New("wrong vault password")
+	}
+
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error when decrypting vault: %s", err)
+	}
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCTR(block, iv).XORKeyStream(plaintext, ciphertext)
+
+	// Plaintexts are padded like CBC ones
+	padding := 0
+	if len(plaintext) > 0 {
+		padding = int(plaintext[len(plaintext)-1])
+	}
+	if padding == 0 || padding > aes.BlockSize || padding > len(plaintext) {
+		return nil, nil, errors.New("invalid vault padding")
+	}
+
+	return envelope, plaintext[:len(plaintext)-padding], nil
+}
+
+// encryptVault
+//
+// Encrypts a file with ansible-vault, keeping
+// the header of the file it was read from. A
+// new salt is generated, as ansible-vault does.
+func encryptVault(envelope *vaultEnvelope, plaintext, password []byte) ([]byte, error) {
+	salt := make([]byte, vaultSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("error when generating vault salt: %s", err)
+	}
+	cipherKey, hmacKey, iv := deriveVaultKeys(password, salt)
+
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	padded := append(bytes.Clone(plaintext), bytes.Repeat([]byte{byte(padding)}, padding)...)
+
+	block, err := aes.NewCipher(cipherKey)
+	if err != nil {
+		return nil, fmt.Errorf("error when encrypting vault: %s", err)
+	}
+	ciphertext := make([]byte, len(padded))
+	cipher.NewCTR(block, iv).XORKeyStream(ciphertext, padded)
+
+	mac := hmac.New(sha256.New, hmacKey)
+	mac.Write(ciphertext)
+
+	payload := hex.EncodeToString([]byte(strings.Join([]string{
+		hex.EncodeToString(salt),
+		hex.EncodeToString(mac.Sum(nil)),
+		hex.EncodeToString(ciphertext),
+	}, "\n")))
+
+	header := []string{vaultPrefix, envelope.version, vaultCipher}
+	if envelope.label != "" {
+		header = append(header, envelope.label)
+	}
+	lines := []string{strings.Join(header, ";")}
+	for len(payload) > 0 {
+		n := min(len(payload), vaultLineLength)
+		lines, payload = append(lines, payload[:n]), payload[n:]
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// openVault
+//
+// Returns the plaintext of a file read from the
+// repository, along with its vault envelope when
+// it is encrypted with ansible-vault.
+func openVault(req UpdateReleaseReq, content string) (string, *vaultEnvelope, error) {
+	if !isVaultFile([]byte(content)) {
+		return content, nil, nil
+	}
+	if req.VaultPassword == "" {
+		return "", nil, fmt.Errorf("error when decrypting %q: %w", req.Repo.Path, errNoVaultPassword)
+	}
+
+	envelope, plaintext, err := decryptVault([]byte(content), []byte(req.VaultPassword))
+	if err != nil {
+		return "", nil, fmt.Errorf("error when decrypting %q: %s", req.Repo.Path, err)
+	}
+
+	return string(plaintext), envelope, nil
+}
@@ -0,0 +1,241 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	legacy "github.com/cguertin14/k3supdater/pkg/github"
+	github_mocks "github.com/cguertin14/k3supdater/pkg/github/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-github/v57/github"
+)
+
+// vaultPassword is the password of
+// testdata/vault/all.yml.
+const vaultPassword = "some password"
+
+const vaultPlaintext = "---\nk3s_release_version: v1.28.5+k3s1\nk3s_token: some-secret-token\n"
+
+func TestDecryptVault(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/vault/all.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		content  []byte
+		password string
+
+		expectedLabel string
+		expectError   bool
+	}{
+		"success case with no error": {
+			content:       fixture,
+			password:      vaultPassword,
+			expectedLabel: "prod",
+		},
+		"error case with wrong password": {
+			content:     fixture,
+			password:    "some other password",
+			expectError: true,
+		},
+		"error case with unsupported cipher": {
+			content:     []byte("$ANSIBLE_VAULT;1.1;AES\n6162636465\n"),
+			password:    vaultPassword,
+			expectError: true,
+		},
+		"error case with invalid payload": {
+			content:     []byte("$ANSIBLE_VAULT;1.1;AES256\nsome payload\n"),
+			password:    vaultPassword,
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			envelope, plaintext, err := decryptVault(c.content, []byte(c.password))
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if envelope.label != c.expectedLabel {
+				t.Fatalf("expected label %q, got %q", c.expectedLabel, envelope.label)
+			}
+			if string(plaintext) != vaultPlaintext {
+				t.Fatalf("expected %q, got %q", vaultPlaintext, plaintext)
+			}
+		})
+	}
+}
+
+func TestEncryptVault(t *testing.T) {
+	cases := map[string]struct {
+		envelope *vaultEnvelope
+
+		expectedHeader string
+	}{
+		"format 1.1": {
+			envelope:       &vaultEnvelope{version: "1.1"},
+			expectedHeader: "$ANSIBLE_VAULT;1.1;AES256\n",
+		},
+		"format 1.2 with a vault id": {
+			envelope:       &vaultEnvelope{version: "1.2", label: "prod"},
+			expectedHeader: "$ANSIBLE_VAULT;1.2;AES256;prod\n",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			content, err := encryptVault(c.envelope, []byte(vaultPlaintext), []byte(vaultPassword))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if !strings.HasPrefix(string(content), c.expectedHeader) {
+				t.Fatalf("expected header %q, got %q", c.expectedHeader, content)
+			}
+			for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n")[1:] {
+				if len(line) > vaultLineLength {
+					t.Fatalf("expected lines of at most %d characters, got %q", vaultLineLength, line)
+				}
+			}
+
+			envelope, plaintext, err := decryptVault(content, []byte(vaultPassword))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if *envelope != *c.envelope || string(plaintext) != vaultPlaintext {
+				t.Fatalf("expected %v %q, got %v %q", c.envelope, vaultPlaintext, envelope, plaintext)
+			}
+		})
+	}
+}
+
+func TestUpdateK3sReleaseVault(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/vault/all.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		password string
+
+		expectError bool
+	}{
+		"success case with no error": {
+			password: vaultPassword,
+		},
+		"error case with no password": {
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// create new mock client instance
+			githubMockClient := github_mocks.NewMockClient(ctrl)
+
+			// define mock behavior
+			githubMockClient.EXPECT().GetRepositoryContents(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(&github.RepositoryContent{
+					Content: github.String(base64.StdEncoding.EncodeToString(fixture)),
+				}, nil, nil, nil)
+
+			var committed string
+			if !c.expectError {
+				githubMockClient.EXPECT().GetRepositoryReleases(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]*github.RepositoryRelease{
+						{Name: github.String("v1.28.6+k3s1"), Body: github.String("some release notes")},
+						{Name: github.String("v1.28.5+k3s1"), Body: github.String("some release notes")},
+					}, nil, nil)
+				githubMockClient.EXPECT().GetBranch(gomock.Any(), gomock.Any()).
+					Times(2).
+					Return(&github.Reference{
+						Object: &github.GitObject{},
+					}, nil, nil)
+				githubMockClient.EXPECT().CreateBranch(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, nil, nil)
+				githubMockClient.EXPECT().GetCommit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Commit{Tree: &github.Tree{}}, nil, nil)
+				// the base tree, for the modes of the committed files
+				githubMockClient.EXPECT().GetTree(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Tree{}, nil, nil)
+				githubMockClient.EXPECT().CreateBlob(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, req legacy.CreateBlobRequest) (*github.Blob, *github.Response, error) {
+						content, _ := base64.StdEncoding.DecodeString(req.GetContent())
+						committed = string(content)
+						return &github.Blob{SHA: github.String("some sha")}, nil, nil
+					})
+				githubMockClient.EXPECT().CreateTree(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Tree{}, nil, nil)
+				githubMockClient.EXPECT().CreateCommit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(&github.Commit{}, nil, nil)
+				githubMockClient.EXPECT().UpdateRef(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, nil, nil)
+				githubMockClient.EXPECT().CreatePullRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, nil, nil)
+			}
+
+			// create mock updater client
+			client := NewClient(context.Background(), Dependencies{
+				Client: githubMockClient,
+			})
+
+			err := client.UpdateK3sRelease(context.Background(), UpdateReleaseReq{
+				Repo: Repository{
+					Owner:  "some owner",
+					Name:   "some name",
+					Path:   "inventory/prod/group_vars/all.yml",
+					Branch: "main",
+				},
+				ReleaseRepo: Repository{
+					Owner: "k3s-io",
+					Name:  "k3s",
+				},
+				VaultPassword: c.password,
+			})
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// The file is committed encrypted
+			envelope, plaintext, err := decryptVault([]byte(committed), []byte(vaultPassword))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			expected := strings.Replace(vaultPlaintext, "v1.28.5+k3s1", "v1.28.6+k3s1", 1)
+			if envelope.label != "prod" || string(plaintext) != expected {
+				t.Fatalf("expected %q, got %q", expected, plaintext)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package updater
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// k3sBuildPrefix prefixes the revision of a
+// k3s release, i.e.: "+k3s2" in v1.30.4+k3s2.
+const k3sBuildPrefix string = "+k3s"
+
+// compareK3sVersions
+//
+// Compares two k3s versions like semver.Compare, except
+// that the k3s revision of the build metadata is ordered
+// as well, so that a respin of the same Kubernetes patch
+// version (i.e.: v1.30.4+k3s2) is newer than the previous
+// one (i.e.: v1.30.4+k3s1).
+func compareK3sVersions(v, w string) int {
+	if compared := semver.Compare(v, w); compared != 0 {
+		return compared
+	}
+
+	vRevision, wRevision := k3sRevision(v), k3sRevision(w)
+	switch {
+	case vRevision < wRevision:
+		return -1
+	case vRevision > wRevision:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// oldestK3sVersion
+//
+// Returns the oldest of the versions pinned by a
+// file. Pins may have drifted apart, in which case
+// the oldest one is the current version, so that
+// all of them catch up.
+func oldestK3sVersion(versions []string) string {
+	var oldest string
+	for _, version := range versions {
+		if oldest == "" || compareK3sVersions(version, oldest) < 0 {
+			oldest = version
+		}
+	}
+	return oldest
+}
+
+// k3sRevision
+//
+// Returns the k3s revision of a version, or 0 when its
+// build metadata doesn't follow the "+k3sN" format.
+func k3sRevision(version string) int {
+	build := semver.Build(version)
+	if !strings.HasPrefix(build, k3sBuildPrefix) {
+		return 0
+	}
+
+	revision, err := strconv.Atoi(strings.TrimPrefix(build, k3sBuildPrefix))
+	if err != nil || revision < 0 {
+		return 0
+	}
+
+	return revision
+}
@@ -0,0 +1,54 @@
+//go:build test
+// +build test
+
+package updater
+
+import "testing"
+
+func TestCompareK3sVersions(t *testing.T) {
+	cases := map[string]struct {
+		v, w     string
+		expected int
+	}{
+		"respin is newer":                            {v: "v1.30.4+k3s2", w: "v1.30.4+k3s1", expected: 1},
+		"respin is older":                            {v: "v1.30.4+k3s1", w: "v1.30.4+k3s2", expected: -1},
+		"revisions are compared as numbers":          {v: "v1.30.4+k3s10", w: "v1.30.4+k3s9", expected: 1},
+		"same version and revision":                  {v: "v1.30.4+k3s1", w: "v1.30.4+k3s1", expected: 0},
+		"patch version wins over revision":           {v: "v1.30.5+k3s1", w: "v1.30.4+k3s3", expected: 1},
+		"minor version wins over revision":           {v: "v1.29.9+k3s3", w: "v1.30.0+k3s1", expected: -1},
+		"missing revision is older":                  {v: "v1.30.4", w: "v1.30.4+k3s1", expected: -1},
+		"unknown build metadata is like no revision": {v: "v1.30.4+rke2r1", w: "v1.30.4", expected: 0},
+		"invalid revision is like no revision":       {v: "v1.30.4+k3sfoo", w: "v1.30.4+k3s1", expected: -1},
+		"release candidate is older than release":    {v: "v1.30.4-rc1+k3s2", w: "v1.30.4+k3s1", expected: -1},
+		"release candidate respin is newer":          {v: "v1.30.4-rc1+k3s2", w: "v1.30.4-rc1+k3s1", expected: 1},
+		"invalid version is older":                   {v: "some version", w: "v1.30.4+k3s1", expected: -1},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := compareK3sVersions(c.v, c.w); got != c.expected {
+				t.Fatalf("expected compareK3sVersions(%q, %q) to be %d, got %d", c.v, c.w, c.expected, got)
+			}
+		})
+	}
+}
+
+func TestOldestK3sVersion(t *testing.T) {
+	cases := map[string]struct {
+		versions []string
+		expected string
+	}{
+		"single version":       {versions: []string{"v1.30.4+k3s1"}, expected: "v1.30.4+k3s1"},
+		"drifted versions":     {versions: []string{"v1.30.4+k3s1", "v1.29.8+k3s1", "v1.30.2+k3s1"}, expected: "v1.29.8+k3s1"},
+		"respins of a version": {versions: []string{"v1.30.4+k3s2", "v1.30.4+k3s1"}, expected: "v1.30.4+k3s1"},
+		"no version":           {expected: ""},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := oldestK3sVersion(c.versions); got != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, got)
+			}
+		})
+	}
+}
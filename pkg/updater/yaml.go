@@ -0,0 +1,368 @@
+package updater
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// errKeyNotFound is returned when looking
+// up a key which isn't defined in a file.
+var errKeyNotFound = errors.New("key not found")
+
+// yamlScalar is a scalar value located in
+// the source of a yaml document.
+type yamlScalar struct {
+	node *yaml.Node
+
+	// start and end are the byte offsets of the
+	// scalar in the source, quotes included. They
+	// are equal when the value is empty.
+	start int
+	end   int
+}
+
+// findYAMLScalar
+//
+// Locates the scalar value of a key path in the first
+// yaml document defining it. Aliases are followed, so
+// that the anchored value is the one returned.
+func findYAMLScalar(content []byte, path []string) (*yamlScalar, error) {
+	docs, err := yamlDocuments(content)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range docs {
+		if node := lookupYAMLNode(doc, path); node != nil {
+			return locateYAMLScalar(content, node, path)
+		}
+	}
+
+	return nil, fmt.Errorf("%q %w", strings.Join(path, "."), errKeyNotFound)
+}
+
+// yamlDocuments
+//
+// Decodes every document of a yaml stream. Node
+// positions are relative to the whole stream.
+func yamlDocuments(content []byte) ([]*yaml.Node, error) {
+	docs := make([]*yaml.Node, 0)
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		doc := &yaml.Node{}
+		if err := decoder.Decode(doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, fmt.Errorf("error when parsing yaml: %s", err)
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// locateYAMLScalar
+//
+// Returns the position of the value node
+// found at a key path in the source.
+func locateYAMLScalar(content []byte, node *yaml.Node, path []string) (*yamlScalar, error) {
+	if node.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("%q is not a scalar value", strings.Join(path, "."))
+	}
+
+	// Inline vault values are never edited
+	if node.Tag == "!vault" {
+		return nil, fmt.Errorf("%q is encrypted inline with ansible-vault, only whole files can be decrypted", strings.Join(path, "."))
+	}
+
+	start, end, err := yamlScalarBounds(content, node)
+	if err != nil {
+		return nil, fmt.Errorf("error when locating %q: %s", strings.Join(path, "."), err)
+	}
+
+	return &yamlScalar{node: node, start: start, end: end}, nil
+}
+
+// lookupYAMLNode
+//
+// Walks mappings following the key path, and
+// returns the matching value node if any.
+func lookupYAMLNode(node *yaml.Node, path []string) *yaml.Node {
+	for node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+			continue
+		}
+		if len(node.Content) == 0 {
+			return nil
+		}
+		node = node.Content[0]
+	}
+
+	if len(path) == 0 {
+		return node
+	}
+	if node.Kind == yaml.SequenceNode {
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index >= len(node.Content) {
+			return nil
+		}
+		return lookupYAMLNode(node.Content[index], path[1:])
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == path[0] {
+			return lookupYAMLNode(node.Content[i+1], path[1:])
+		}
+	}
+
+	// Keys defined in the mapping take precedence
+	// over the merged ones (i.e.: "<<: *defaults").
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag == "!!merge" {
+			if found := lookupYAMLNode(node.Content[i+1], path); found != nil {
+				return found
+			}
+		}
+	}
+
+	return nil
+}
+
+// walkYAMLScalars
+//
+// Calls fn for every scalar value of a document,
+// along with its key path, in the format read by
+// parseKeyPath. Aliases and merge keys are not
+// followed, values being reported where defined.
+func walkYAMLScalars(node *yaml.Node, keyPath string, fn func(keyPath string, node *yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkYAMLScalars(child, keyPath, fn)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			walkYAMLScalars(child, fmt.Sprintf("%s[%d]", keyPath, i), fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Tag == "!!merge" {
+				continue
+			}
+
+			child := key.Value
+			switch {
+			case strings.ContainsAny(child, ".[]'"):
+				child = fmt.Sprintf(`["%s"]`, child)
+			case keyPath != "":
+				child = "." + child
+			}
+			walkYAMLScalars(node.Content[i+1], keyPath+child, fn)
+		}
+	case yaml.ScalarNode:
+		if keyPath != "" {
+			fn(keyPath, node)
+		}
+	}
+}
+
+// parseKeyPath
+//
+// Splits a key path into its keys. Keys are separated
+// with dots, and can be put between brackets and quotes
+// when they contain dots themselves. A leading "$" is
+// allowed, so that JSONPath-like paths can be used:
+//
+//	k3s_release_version
+//	k3s.version
+//	$.k3s['release.version']
+//	k3s_clusters[0].version
+func parseKeyPath(raw string) ([]string, error) {
+	rest := strings.TrimPrefix(strings.TrimSpace(raw), "$")
+	path := make([]string, 0)
+	invalid := func(reason string) error {
+		return fmt.Errorf("error when parsing key path %q: %s", raw, reason)
+	}
+
+	for len(rest) > 0 {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			if rest == "" || rest[0] == '.' || rest[0] == '[' {
+				return nil, invalid("empty key")
+			}
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if len(rest) > 1 && (rest[1] == '\'' || rest[1] == '"') {
+				end = strings.Index(rest[2:], string(rest[1])+"]")
+				if end >= 0 {
+					// skip the opening and closing
+					// quotes, and the brackets.
+					end += 4
+				}
+			} else if end >= 0 {
+				end++
+			}
+			if end < 0 {
+				return nil, invalid("unterminated bracket")
+			}
+
+			key := strings.Trim(rest[1:end-1], `'"`)
+			if key == "" {
+				return nil, invalid("empty key")
+			}
+			path = append(path, key)
+			rest = rest[end:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			path = append(path, rest[:end])
+			rest = rest[end:]
+		}
+	}
+
+	if len(path) == 0 {
+		return nil, invalid("empty path")
+	}
+
+	return path, nil
+}
+
+// setYAMLScalar
+//
+// Rewrites the scalar value of a key path in place,
+// keeping its quoting style. The rest of the source,
+// comments and layout included, is left untouched.
+func setYAMLScalar(content []byte, path []string, value string) ([]byte, error) {
+	scalar, err := findYAMLScalar(content, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return replaceYAMLScalars(content, []*yamlScalar{scalar}, value), nil
+}
+
+// replaceYAMLScalars
+//
+// Rewrites several scalars of the same source
+// with a single value, keeping their quoting style.
+func replaceYAMLScalars(content []byte, scalars []*yamlScalar, value string) []byte {
+	replacements := make([]replacement, 0, len(scalars))
+	for _, scalar := range scalars {
+		r := replacement{start: scalar.start, end: scalar.end, value: value}
+		switch {
+		case scalar.start == scalar.end:
+			// empty values have no source to replace,
+			// so the new value follows the colon.
+			r.value = " " + value
+		case scalar.node.Style&yaml.DoubleQuotedStyle != 0:
+			r.value = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+		case scalar.node.Style&yaml.SingleQuotedStyle != 0:
+			r.value = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+		}
+		replacements = append(replacements, r)
+	}
+
+	return replaceRanges(content, replacements)
+}
+
+// yamlScalarBounds
+//
+// Returns the byte offsets of a scalar in the
+// source, skipping its anchor and tag if any.
+func yamlScalarBounds(content []byte, node *yaml.Node) (start, end int, err error) {
+	start = yamlOffset(content, node.Line, node.Column)
+
+	// Empty values are positioned right after
+	// the colon of their key.
+	if node.Tag == "!!null" && node.Value == "" {
+		return start, start, nil
+	}
+
+	// Skip properties, i.e.: "&anchor !!str value"
+	for start < len(content) && (content[start] == '&' || content[start] == '!') {
+		for start < len(content) && !isYAMLSpace(content[start]) {
+			start++
+		}
+		for start < len(content) && isYAMLSpace(content[start]) {
+			start++
+		}
+	}
+
+	switch {
+	case node.Style&yaml.DoubleQuotedStyle != 0:
+		end = start + 1
+		for end < len(content) && content[end] != '"' {
+			if content[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end++
+	case node.Style&yaml.SingleQuotedStyle != 0:
+		end = start + 1
+		for end < len(content) {
+			if content[end] == '\'' {
+				if end+1 < len(content) && content[end+1] == '\'' {
+					end += 2
+					continue
+				}
+				break
+			}
+			end++
+		}
+		end++
+	case node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return 0, 0, errors.New("block scalars are not supported")
+	default:
+		end = start + len(node.Value)
+		if end > len(content) || string(content[start:end]) != node.Value {
+			return 0, 0, errors.New("multi-line plain scalars are not supported")
+		}
+	}
+
+	if end > len(content) {
+		return 0, 0, errors.New("unterminated quoted scalar")
+	}
+
+	return start, end, nil
+}
+
+// yamlOffset
+//
+// Converts a 1-based line and column, as
+// reported by the parser, to a byte offset.
+func yamlOffset(content []byte, line, column int) int {
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := bytes.IndexByte(content[offset:], '\n')
+		if i < 0 {
+			return len(content)
+		}
+		offset += i + 1
+	}
+
+	for c := 1; c < column && offset < len(content); c++ {
+		_, size := utf8.DecodeRune(content[offset:])
+		offset += size
+	}
+
+	return offset
+}
+
+func isYAMLSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
@@ -0,0 +1,219 @@
+//go:build test
+// +build test
+
+package updater
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files of the yaml tests")
+
+func TestSetYAMLScalarGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "yaml", "*.input.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no golden test input found")
+	}
+
+	// every input defines the version as
+	// v1.29.3+k3s1, except for these ones.
+	currentVersions := map[string]string{
+		"empty_value": "",
+	}
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".input.yml")
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedVersion, ok := currentVersions[name]
+			if !ok {
+				expectedVersion = "v1.29.3+k3s1"
+			}
+			scalar, err := findYAMLScalar(content, []string{DefaultVersionKey})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if scalar.node.Value != expectedVersion {
+				t.Fatalf("expected current version %q, got %q", expectedVersion, scalar.node.Value)
+			}
+
+			updated, err := setYAMLScalar(content, []string{DefaultVersionKey}, "v1.30.4+k3s1")
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			golden := filepath.Join("testdata", "yaml", name+".golden.yml")
+			if *updateGolden {
+				if err = os.WriteFile(golden, updated, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(updated) != string(expected) {
+				t.Fatalf("expected:\n%s\ngot:\n%s", expected, updated)
+			}
+
+			// the updated document must still be
+			// valid and hold the new version.
+			scalar, err = findYAMLScalar(updated, []string{DefaultVersionKey})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if scalar.node.Value != "v1.30.4+k3s1" {
+				t.Fatalf("expected updated version %q, got %q", "v1.30.4+k3s1", scalar.node.Value)
+			}
+		})
+	}
+}
+
+func TestFindYAMLScalarErrors(t *testing.T) {
+	cases := map[string]string{
+		"missing key":         "ansible_user: pi\n",
+		"invalid yaml":        "k3s_release_version: [v1.29.3+k3s1\n",
+		"non scalar value":    "k3s_release_version:\n  - v1.29.3+k3s1\n",
+		"block scalar":        "k3s_release_version: |\n  v1.29.3+k3s1\n",
+		"multi-line scalar":   "k3s_release_version: v1.29.3\n  +k3s1\n",
+		"top level sequence":  "- k3s_release_version: v1.29.3+k3s1\n",
+		"empty document list": "",
+	}
+
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := findYAMLScalar([]byte(content), []string{DefaultVersionKey}); err == nil {
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestParseKeyPath(t *testing.T) {
+	cases := map[string]struct {
+		raw string
+
+		expected    []string
+		expectError bool
+	}{
+		"single key":               {raw: "k3s_release_version", expected: []string{"k3s_release_version"}},
+		"dotted keys":              {raw: "k3s.version", expected: []string{"k3s", "version"}},
+		"jsonpath root":            {raw: "$.k3s.version", expected: []string{"k3s", "version"}},
+		"quoted bracket key":       {raw: "$.k3s['release.version']", expected: []string{"k3s", "release.version"}},
+		"double quoted bracket":    {raw: `k3s["version"].pinned`, expected: []string{"k3s", "version", "pinned"}},
+		"sequence index":           {raw: "k3s_clusters[0].version", expected: []string{"k3s_clusters", "0", "version"}},
+		"leading bracket":          {raw: "$['k3s_version']", expected: []string{"k3s_version"}},
+		"error case empty path":    {raw: "$", expectError: true},
+		"error case empty key":     {raw: "k3s..version", expectError: true},
+		"error case trailing dot":  {raw: "k3s.", expectError: true},
+		"error case open bracket":  {raw: "k3s['version", expectError: true},
+		"error case empty bracket": {raw: "k3s[]", expectError: true},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			path, err := parseKeyPath(c.raw)
+			if c.expectError {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if strings.Join(path, "|") != strings.Join(c.expected, "|") {
+				t.Fatalf("expected %q, got %q", c.expected, path)
+			}
+		})
+	}
+}
+
+func TestSetYAMLScalarKeyPaths(t *testing.T) {
+	content := `k3s_version: v1.29.3+k3s1
+k3s:
+  version: "v1.29.3+k3s1" # nested
+  release.version: v1.29.3+k3s1
+k3s_clusters:
+  - name: prod
+    version: v1.29.3+k3s1
+  - name: lab
+    version: v1.29.3+k3s1
+`
+
+	cases := map[string]struct {
+		key string
+
+		expectedLine string
+		expectError  bool
+	}{
+		"success case with another key name": {
+			key:          "k3s_version",
+			expectedLine: "k3s_version: v1.30.4+k3s1",
+		},
+		"success case with nested key": {
+			key:          "k3s.version",
+			expectedLine: `  version: "v1.30.4+k3s1" # nested`,
+		},
+		"success case with dotted key name": {
+			key:          "$.k3s['release.version']",
+			expectedLine: "  release.version: v1.30.4+k3s1",
+		},
+		"success case with sequence index": {
+			key:          "k3s_clusters[1].version",
+			expectedLine: "    version: v1.30.4+k3s1",
+		},
+		"error case with out of range index": {
+			key:         "k3s_clusters[2].version",
+			expectError: true,
+		},
+		"error case with mapping value": {
+			key:         "k3s",
+			expectError: true,
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			path, err := parseKeyPath(c.key)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			updated, err := setYAMLScalar([]byte(content), path, "v1.30.4+k3s1")
+			if c.expectError {
+				if err == nil {
+					t.FailNow()
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			// exactly one line must have changed
+			before, after := strings.Split(content, "\n"), strings.Split(string(updated), "\n")
+			changed := make([]string, 0)
+			for i := range before {
+				if before[i] != after[i] {
+					changed = append(changed, after[i])
+				}
+			}
+			if len(changed) != 1 || changed[0] != c.expectedLine {
+				t.Fatalf("expected only %q to change, got %q", c.expectedLine, changed)
+			}
+		})
+	}
+}